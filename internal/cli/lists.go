@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/rpc"
+	"os"
+	"text/tabwriter"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/simachri/taskwarrior-ms-todo/internal/server"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+type listsReadCmd struct {
+	output  *string
+	account *string
+	cmd     *cobra.Command
+}
+
+// listOutput is the JSON representation of a MS To-Do list.
+type listOutput struct {
+	DisplayName       string `json:"displayName"`
+	ID                string `json:"id"`
+	WellknownListName string `json:"wellknownListName"`
+	IsOwner           bool   `json:"isOwner"`
+	IsShared          bool   `json:"isShared"`
+}
+
+func (cmd *listsReadCmd) exec() error {
+	if *cmd.output != outputFormatText && *cmd.output != outputFormatJSON {
+		return errors.New(fmt.Sprintf(
+			"Unknown output format '%s', use '%s' or '%s'.",
+			*cmd.output,
+			outputFormatText,
+			outputFormatJSON,
+		))
+	}
+
+	rpcClient, err := rpc.Dial("tcp", "127.0.0.1:41001")
+	if err != nil {
+		return err
+	}
+	defer rpcClient.Close()
+
+	resp := new(server.Response)
+	err = rpcClient.Call(server.ListsReadCmd, &server.Request{
+		Account: *cmd.account,
+	}, resp)
+	if err != nil {
+		return err
+	}
+
+	if *cmd.output == outputFormatJSON {
+		return printListsJSON(resp.Lists)
+	}
+	return printListsText(resp.Lists)
+}
+
+func printListsJSON(lists []models.TaskList) error {
+	output := []listOutput{}
+	for _, list := range lists {
+		output = append(output, listOutput{
+			DisplayName:       list.DisplayName,
+			ID:                list.ID,
+			WellknownListName: list.WellknownListName,
+			IsOwner:           list.IsOwner,
+			IsShared:          list.IsShared,
+		})
+	}
+
+	outputJSON, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(outputJSON))
+
+	return nil
+}
+
+func printListsText(lists []models.TaskList) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tID\tWELL-KNOWN\tACCESS")
+	for _, list := range lists {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\n",
+			list.DisplayName,
+			list.ID,
+			list.WellknownListName,
+			listAccess(&list),
+		)
+	}
+	return writer.Flush()
+}
+
+// listAccess returns whether the list is owned by the user and/or shared.
+func listAccess(list *models.TaskList) string {
+	switch {
+	case list.IsOwner && list.IsShared:
+		return "owned, shared"
+	case list.IsOwner:
+		return "owned"
+	}
+	return "shared"
+}
+
+func addListsCmd(parentCmd *cobra.Command) {
+	listsCmd := &listsReadCmd{}
+
+	c := &cobra.Command{
+		Use:   "lists",
+		Short: "Show To-Do lists",
+		Long: `Shows the MS To-Do lists with their display name, ID, well-known list name ` +
+			`and whether they are owned or shared`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listsCmd.exec()
+		},
+	}
+
+	listsCmd.output = c.PersistentFlags().
+		StringP("output", "o", outputFormatText,
+			fmt.Sprintf("Output format: '%s' or '%s'", outputFormatText, outputFormatJSON))
+
+	listsCmd.account = c.PersistentFlags().
+		StringP("account", "a", "", "Account of the credentials.yaml")
+
+	listsCmd.cmd = c
+
+	parentCmd.AddCommand(c)
+}
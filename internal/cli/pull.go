@@ -15,12 +15,20 @@ type command interface {
 }
 
 type tasksPullCmd struct {
-	listID    *string
-	getListID func() *string
-	cmd       *cobra.Command
+	listID     *string
+	account    *string
+	getListID  func() *string
+	getAccount func() *string
+	getLists   func() ([]server.ListMapping, error)
+	cmd        *cobra.Command
 }
 
 func (cmd *tasksPullCmd) exec() error {
+	lists, err := cmd.getLists()
+	if err != nil {
+		return err
+	}
+
 	rpcClient, err := rpc.Dial("tcp", "127.0.0.1:41001")
 	if err != nil {
 		return err
@@ -29,7 +37,9 @@ func (cmd *tasksPullCmd) exec() error {
 
 	resp := new(server.Response)
 	err = rpcClient.Call(server.TasksPullCmd, &server.Request{
-		ListID: *cmd.getListID(),
+		Account: *cmd.getAccount(),
+		ListID:  *cmd.getListID(),
+		Lists:   lists,
 	}, resp)
 	if err != nil {
 		return err
@@ -45,7 +55,9 @@ func addPullCmd(parentCmd *cobra.Command, configAdapter *viper.Viper) {
 	c := &cobra.Command{
 		Use:   "pull",
 		Short: "Pull tasks",
-		Long:  `Pulls the tasks from a MS To-Do list and creates them as tasks in Taskwarrior`,
+		Long: `Pulls the tasks from a MS To-Do list and creates them as tasks in ` +
+			`Taskwarrior. If no list is given, all lists of the config 'sync.lists' ` +
+			`are pulled, or those of the given account.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return pullCmd.exec()
 		},
@@ -55,8 +67,9 @@ func addPullCmd(parentCmd *cobra.Command, configAdapter *viper.Viper) {
 	listIDConfigPath := "sync.pull.list_id"
 	pullCmd.listID = c.PersistentFlags().
 		StringP(listIDFlagName, "l", "",
-			fmt.Sprintf("MS To-Do Tasklist ID (if not provided, then it is read "+
-				"from config path %s)", listIDConfigPath))
+			fmt.Sprintf("MS To-Do Tasklist ID, display name, 'defaultList' or "+
+				"'flaggedEmails' (if not provided, then it is read from config path %s)",
+				listIDConfigPath))
 	configAdapter.BindPFlag(
 		listIDConfigPath,
 		c.PersistentFlags().Lookup(listIDFlagName),
@@ -66,6 +79,34 @@ func addPullCmd(parentCmd *cobra.Command, configAdapter *viper.Viper) {
 		return &listID
 	}
 
+	accountFlagName := "account"
+	accountConfigPath := "sync.pull.account"
+	pullCmd.account = c.PersistentFlags().
+		StringP(accountFlagName, "a", "",
+			fmt.Sprintf("Account of the credentials.yaml (if not provided, then it is "+
+				"read from config path %s)", accountConfigPath))
+	configAdapter.BindPFlag(
+		accountConfigPath,
+		c.PersistentFlags().Lookup(accountFlagName),
+	)
+	pullCmd.getAccount = func() *string {
+		account := configAdapter.GetString(accountConfigPath)
+		return &account
+	}
+
+	listsConfigPath := "sync.lists"
+	pullCmd.getLists = func() ([]server.ListMapping, error) {
+		var lists []server.ListMapping
+		err := configAdapter.UnmarshalKey(listsConfigPath, &lists)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"[Config] Failed to read key '%s' from config.yaml.",
+				listsConfigPath,
+			)
+		}
+		return lists, nil
+	}
+
 	pullCmd.cmd = c
 
 	parentCmd.AddCommand(c)
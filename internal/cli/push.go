@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"net/rpc"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type tasksPushCmd struct {
+	listID      *string
+	account     *string
+	filter      *string
+	getListID   func() *string
+	getAccount  func() *string
+	getFilter   func() *string
+	getProjects func() []string
+	cmd         *cobra.Command
+}
+
+func (cmd *tasksPushCmd) exec() error {
+	rpcClient, err := rpc.Dial("tcp", "127.0.0.1:41001")
+	if err != nil {
+		return err
+	}
+	defer rpcClient.Close()
+
+	resp := new(server.Response)
+	err = rpcClient.Call(server.TasksPushCmd, &server.Request{
+		Account:  *cmd.getAccount(),
+		ListID:   *cmd.getListID(),
+		Filter:   *cmd.getFilter(),
+		Projects: cmd.getProjects(),
+	}, resp)
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp.Message)
+
+	return nil
+}
+
+func addPushCmd(parentCmd *cobra.Command, configAdapter *viper.Viper) {
+	pushCmd := &tasksPushCmd{}
+
+	c := &cobra.Command{
+		Use:   "push",
+		Short: "Push tasks",
+		Long: `Creates the Taskwarrior tasks that are not yet linked to MS To-Do as tasks ` +
+			`in a MS To-Do list. If no list is given, the tasks of the projects matching ` +
+			`the config 'sync.push.projects' are pushed to a MS To-Do list per project.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pushCmd.exec()
+		},
+	}
+
+	listIDFlagName := "list"
+	listIDConfigPath := "sync.push.list_id"
+	pushCmd.listID = c.PersistentFlags().
+		StringP(listIDFlagName, "l", "",
+			fmt.Sprintf("MS To-Do Tasklist ID, display name, 'defaultList' or "+
+				"'flaggedEmails' (if not provided, then it is read from config path %s)",
+				listIDConfigPath))
+	configAdapter.BindPFlag(
+		listIDConfigPath,
+		c.PersistentFlags().Lookup(listIDFlagName),
+	)
+	pushCmd.getListID = func() *string {
+		listID := configAdapter.GetString(listIDConfigPath)
+		return &listID
+	}
+
+	accountFlagName := "account"
+	accountConfigPath := "sync.push.account"
+	pushCmd.account = c.PersistentFlags().
+		StringP(accountFlagName, "a", "",
+			fmt.Sprintf("Account of the credentials.yaml (if not provided, then it is "+
+				"read from config path %s)", accountConfigPath))
+	configAdapter.BindPFlag(
+		accountConfigPath,
+		c.PersistentFlags().Lookup(accountFlagName),
+	)
+	pushCmd.getAccount = func() *string {
+		account := configAdapter.GetString(accountConfigPath)
+		return &account
+	}
+
+	filterFlagName := "filter"
+	filterConfigPath := "sync.push.filter"
+	pushCmd.filter = c.PersistentFlags().
+		StringP(filterFlagName, "f", "",
+			fmt.Sprintf("Taskwarrior filter for the tasks to push (if not provided, "+
+				"then it is read from config path %s)", filterConfigPath))
+	configAdapter.BindPFlag(
+		filterConfigPath,
+		c.PersistentFlags().Lookup(filterFlagName),
+	)
+	pushCmd.getFilter = func() *string {
+		filter := configAdapter.GetString(filterConfigPath)
+		return &filter
+	}
+
+	projectsConfigPath := "sync.push.projects"
+	pushCmd.getProjects = func() []string {
+		return configAdapter.GetStringSlice(projectsConfigPath)
+	}
+
+	pushCmd.cmd = c
+
+	parentCmd.AddCommand(c)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/adrg/xdg"
 	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+	"github.com/simachri/taskwarrior-ms-todo/internal/taskwarrior"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -27,8 +28,13 @@ func Execute() error {
 	graphClientFactory := &mstodo.ClientFactory{
 		// Passing this as function is required as Viper parses the config not before
 		// a command's Execute() function is called.
-		GetTenantID: func() string { return credentialsFileViper.GetString("tenant_id") },
-		GetClientID: func() string { return credentialsFileViper.GetString("client_id") },
+		GetAccounts: getAccounts,
+		GetTokenCachePath: func(account string) (string, error) {
+			if account == mstodo.DefaultAccount {
+				return xdg.DataFile("twtodo/token_cache.json")
+			}
+			return xdg.DataFile(fmt.Sprintf("twtodo/token_cache_%s.json", account))
+		},
 	}
 
     addSetupCmd(rootCmd)
@@ -45,13 +51,76 @@ func Execute() error {
 		}
 		return &config, nil
 	}
-	addUpCmd(rootCmd, graphClientFactory, getUpCmdConfig)
+	getTaskwarriorConfig := func() (*taskwarrior.Config, error) {
+		configKey := "taskwarrior"
+		config := taskwarrior.DefaultConfig()
+		err := cfgFileViper.UnmarshalKey(configKey, &config)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"[Config] Failed to read key '%s' from config.yaml.",
+				configKey,
+			)
+		}
+		return &config, nil
+	}
+	getMSToDoConfig := func() (*mstodo.Config, error) {
+		configKey := "mstodo"
+		config := mstodo.DefaultConfig()
+		err := cfgFileViper.UnmarshalKey(configKey, &config)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"[Config] Failed to read key '%s' from config.yaml.",
+				configKey,
+			)
+		}
+		return &config, nil
+	}
+	addUpCmd(
+		rootCmd,
+		graphClientFactory,
+		getUpCmdConfig,
+		getTaskwarriorConfig,
+		getMSToDoConfig,
+	)
 
 	addPullCmd(rootCmd, cfgFileViper)
 
+	addPushCmd(rootCmd, cfgFileViper)
+
+	addListsCmd(rootCmd)
+
 	return rootCmd.Execute()
 }
 
+// getAccounts reads the credentials of each account of the credentials.yaml. The
+// accounts are given by the key 'accounts'. The top-level fields are the account
+// 'default'.
+func getAccounts() (map[string]mstodo.Credentials, error) {
+	configKey := "accounts"
+	accounts := map[string]mstodo.Credentials{}
+	err := credentialsFileViper.UnmarshalKey(configKey, &accounts)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[Config] Failed to read key '%s' from credentials.yaml.",
+			configKey,
+		)
+	}
+
+	if len(accounts) == 0 ||
+		credentialsFileViper.IsSet("flow") ||
+		credentialsFileViper.IsSet("tenant_id") ||
+		credentialsFileViper.IsSet("client_id") {
+		var credentials mstodo.Credentials
+		err := credentialsFileViper.Unmarshal(&credentials)
+		if err != nil {
+			return nil, fmt.Errorf("[Config] Failed to read credentials.yaml: %w", err)
+		}
+		accounts[mstodo.DefaultAccount] = credentials
+	}
+
+	return accounts, nil
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -3,47 +3,87 @@ package cli
 import (
 	"fmt"
 
+	"github.com/adrg/xdg"
 	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
 	"github.com/simachri/taskwarrior-ms-todo/internal/server"
+	"github.com/simachri/taskwarrior-ms-todo/internal/state"
+	"github.com/simachri/taskwarrior-ms-todo/internal/taskwarrior"
 	"github.com/spf13/cobra"
 )
 
 type UpCmdConfig struct {
 	Port int32
+	// Number of tasks of a list that are pulled concurrently.
+	Workers int
 }
 
 type upCmd struct {
 	cmd *cobra.Command
 	// Using a function is required as Viper parses the config not before a command's
 	// Execute() function is called.
-	GetConfig func() (*UpCmdConfig, error)
+	GetConfig            func() (*UpCmdConfig, error)
+	GetTaskwarriorConfig func() (*taskwarrior.Config, error)
+	GetMSToDoConfig      func() (*mstodo.Config, error)
 }
 
-func (upCmd *upCmd) exec(client mstodo.ClientFacade) error {
+func (upCmd *upCmd) exec(clientFactory *mstodo.ClientFactory) error {
 	config, err := upCmd.GetConfig()
 	if err != nil {
 		return fmt.Errorf("[upCmd] Error: %v", err)
 	}
-	return server.Start(client, &config.Port)
+	twConfig, err := upCmd.GetTaskwarriorConfig()
+	if err != nil {
+		return fmt.Errorf("[upCmd] Error: %v", err)
+	}
+	taskwarrior.Configure(*twConfig)
+	toDoConfig, err := upCmd.GetMSToDoConfig()
+	if err != nil {
+		return fmt.Errorf("[upCmd] Error: %v", err)
+	}
+	// The config of MS To-Do, for example its base URL, is required to authenticate.
+	mstodo.Configure(*toDoConfig)
+
+	clients, err := clientFactory.GetGraphClients()
+	if err != nil {
+		return err
+	}
+
+	stateFilePath, err := xdg.DataFile("twtodo/state.json")
+	if err != nil {
+		return fmt.Errorf("[upCmd] Failed to determine sync state file: %v", err)
+	}
+	store, err := state.Load(stateFilePath)
+	if err != nil {
+		return fmt.Errorf("[upCmd] Error: %v", err)
+	}
+
+	workerCount := config.Workers
+	if workerCount == 0 {
+		workerCount = server.DefaultWorkerCount
+	}
+
+	return server.Start(clients, store, &config.Port, workerCount)
 }
 
 func addUpCmd(
 	parentCmd *cobra.Command,
 	clientFactory *mstodo.ClientFactory,
 	getConfig func() (*UpCmdConfig, error),
+	getTaskwarriorConfig func() (*taskwarrior.Config, error),
+	getMSToDoConfig func() (*mstodo.Config, error),
 ) {
-	upCmd := &upCmd{GetConfig: getConfig}
+	upCmd := &upCmd{
+		GetConfig:            getConfig,
+		GetTaskwarriorConfig: getTaskwarriorConfig,
+		GetMSToDoConfig:      getMSToDoConfig,
+	}
 
 	c := &cobra.Command{
 		Use:   "up",
 		Short: "Start the sync server",
-		Long:  `Starts the sync server and authenticates to MS Azure.`,
+		Long:  `Starts the sync server and authenticates each account to MS Azure.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			authenticatedClient, err := clientFactory.GetGraphClient()
-			if err != nil {
-				return err
-			}
-			return upCmd.exec(authenticatedClient)
+			return upCmd.exec(clientFactory)
 		},
 	}
 	upCmd.cmd = c
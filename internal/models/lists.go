@@ -0,0 +1,19 @@
+package models
+
+const (
+	TODO_WELLKNOWNLIST_NONE          string = "none"
+	TODO_WELLKNOWNLIST_DEFAULTLIST   string = "defaultList"
+	TODO_WELLKNOWNLIST_FLAGGEDEMAILS string = "flaggedEmails"
+)
+
+// TaskList is a MS To-Do task list.
+type TaskList struct {
+	ID          string
+	DisplayName string
+	// 'none', 'defaultList' or 'flaggedEmails'
+	WellknownListName string
+	// 'true' if the authenticated user is the owner of the list.
+	IsOwner bool
+	// 'true' if the list is shared with other users.
+	IsShared bool
+}
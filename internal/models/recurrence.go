@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	TODO_RECURRENCE_DAILY           string = "daily"
+	TODO_RECURRENCE_WEEKLY          string = "weekly"
+	TODO_RECURRENCE_ABSOLUTEMONTHLY string = "absoluteMonthly"
+	TODO_RECURRENCE_RELATIVEMONTHLY string = "relativeMonthly"
+	TODO_RECURRENCE_ABSOLUTEYEARLY  string = "absoluteYearly"
+	TODO_RECURRENCE_RELATIVEYEARLY  string = "relativeYearly"
+
+	TODO_RECURRENCERANGE_ENDDATE  string = "endDate"
+	TODO_RECURRENCERANGE_NOEND    string = "noEnd"
+	TODO_RECURRENCERANGE_NUMBERED string = "numbered"
+
+	// Date format of the recurrence range of MS To-Do, example: 2022-08-02
+	TODO_DATE_FORMAT string = "2006-01-02"
+)
+
+// Recurrence is the recurrence pattern of a MS To-Do task.
+type Recurrence struct {
+	// 'daily', 'weekly', 'absoluteMonthly', 'relativeMonthly', 'absoluteYearly' or
+	// 'relativeYearly'
+	PatternType string
+	// Number of days, weeks, months or years between the occurrences.
+	Interval int32
+	// Days of a 'weekly' pattern, for example 'monday'.
+	DaysOfWeek []string
+	// 'endDate', 'noEnd' or 'numbered'
+	RangeType string
+	// Format is yyyy-MM-DD, example: 2022-08-02
+	EndDate string
+}
+
+// ConvRecurrenceToTW converts a MS To-Do recurrence pattern into the Taskwarrior
+// attributes 'recur' and 'until'. 'until' is empty if the recurrence has no end. An error
+// is returned if the pattern cannot be represented in Taskwarrior.
+func ConvRecurrenceToTW(recurrence *Recurrence, dueAt *string) (
+	recur string,
+	until string,
+	err error,
+) {
+	if recurrence == nil {
+		return "", "", errors.New("[ConvRecurrenceToTW] Recurrence is 'nil'.")
+	}
+	if dueAt == nil || *dueAt == "" {
+		return "", "", errors.New(
+			"[ConvRecurrenceToTW] A recurring task requires a due date in Taskwarrior.")
+	}
+
+	interval := recurrence.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch recurrence.PatternType {
+	case TODO_RECURRENCE_DAILY:
+		recur = convRecurrenceInterval(interval, "daily", "days")
+
+	case TODO_RECURRENCE_WEEKLY:
+		days := make([]string, len(recurrence.DaysOfWeek))
+		for i, day := range recurrence.DaysOfWeek {
+			days[i] = strings.ToLower(day)
+		}
+		sort.Strings(days)
+
+		switch {
+		case len(days) <= 1:
+			recur = convRecurrenceInterval(interval, "weekly", "weeks")
+		case interval == 1 &&
+			strings.Join(days, ",") == "friday,monday,thursday,tuesday,wednesday":
+			recur = "weekdays"
+		default:
+			return "", "", errors.New(fmt.Sprintf(
+				"[ConvRecurrenceToTW] Weekly recurrence on several days '%s' is not "+
+					"supported by Taskwarrior.",
+				strings.Join(recurrence.DaysOfWeek, ", "),
+			))
+		}
+
+	case TODO_RECURRENCE_ABSOLUTEMONTHLY:
+		recur = convRecurrenceInterval(interval, "monthly", "months")
+
+	case TODO_RECURRENCE_ABSOLUTEYEARLY:
+		recur = convRecurrenceInterval(interval, "yearly", "years")
+
+	default:
+		return "", "", errors.New(fmt.Sprintf(
+			"[ConvRecurrenceToTW] Recurrence pattern '%s', for example 'first Monday of "+
+				"the month', is not supported by Taskwarrior.",
+			recurrence.PatternType,
+		))
+	}
+
+	switch recurrence.RangeType {
+	case TODO_RECURRENCERANGE_ENDDATE:
+		endDate, err := time.Parse(TODO_DATE_FORMAT, recurrence.EndDate)
+		if err != nil {
+			return "", "", fmt.Errorf(
+				"[ConvRecurrenceToTW] Failed to parse end date '%s' of recurrence: %w",
+				recurrence.EndDate,
+				err,
+			)
+		}
+		// The recurrence ends after the end date.
+		until = endDate.Add(24*time.Hour - time.Second).Format(TW_DATETIME_FORMAT)
+
+	case TODO_RECURRENCERANGE_NUMBERED:
+		return "", "", errors.New(
+			"[ConvRecurrenceToTW] Recurrence with a number of occurrences is not " +
+				"supported by Taskwarrior.")
+	}
+
+	return recur, until, nil
+}
+
+// convRecurrenceInterval returns the Taskwarrior 'recur' duration for an interval.
+func convRecurrenceInterval(interval int32, single string, unit string) string {
+	if interval == 1 {
+		return single
+	}
+	return fmt.Sprintf("%d%s", interval, unit)
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvRecurrenceToTW_supportedPatterns(t *testing.T) {
+	dueAt := "2022-08-02T00:00:00.0000000"
+	for _, tc := range []struct {
+		recurrence Recurrence
+		recur      string
+	}{
+		{Recurrence{PatternType: TODO_RECURRENCE_DAILY, Interval: 1}, "daily"},
+		{Recurrence{PatternType: TODO_RECURRENCE_DAILY, Interval: 3}, "3days"},
+		{
+			Recurrence{
+				PatternType: TODO_RECURRENCE_WEEKLY,
+				Interval:    2,
+				DaysOfWeek:  []string{"tuesday"},
+			},
+			"2weeks",
+		},
+		{
+			Recurrence{
+				PatternType: TODO_RECURRENCE_WEEKLY,
+				Interval:    1,
+				DaysOfWeek: []string{
+					"monday", "tuesday", "wednesday", "thursday", "friday",
+				},
+			},
+			"weekdays",
+		},
+		{Recurrence{PatternType: TODO_RECURRENCE_ABSOLUTEMONTHLY, Interval: 1}, "monthly"},
+		{Recurrence{PatternType: TODO_RECURRENCE_ABSOLUTEYEARLY, Interval: 2}, "2years"},
+	} {
+		recur, until, err := ConvRecurrenceToTW(&tc.recurrence, &dueAt)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.recur, recur)
+		assert.Equal(t, "", until)
+	}
+}
+
+func TestConvRecurrenceToTW_endDate_isUntil(t *testing.T) {
+	dueAt := "2022-08-02T00:00:00.0000000"
+	recurrence := Recurrence{
+		PatternType: TODO_RECURRENCE_DAILY,
+		Interval:    1,
+		RangeType:   TODO_RECURRENCERANGE_ENDDATE,
+		EndDate:     "2022-09-30",
+	}
+
+	_, until, err := ConvRecurrenceToTW(&recurrence, &dueAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "20220930T235959Z", until)
+}
+
+func TestConvRecurrenceToTW_unsupportedPatterns_isError(t *testing.T) {
+	dueAt := "2022-08-02T00:00:00.0000000"
+	noDueAt := ""
+	for _, tc := range []struct {
+		recurrence Recurrence
+		dueAt      *string
+	}{
+		{Recurrence{PatternType: TODO_RECURRENCE_RELATIVEMONTHLY, Interval: 1}, &dueAt},
+		{
+			Recurrence{
+				PatternType: TODO_RECURRENCE_WEEKLY,
+				Interval:    1,
+				DaysOfWeek:  []string{"monday", "friday"},
+			},
+			&dueAt,
+		},
+		{
+			Recurrence{
+				PatternType: TODO_RECURRENCE_DAILY,
+				RangeType:   TODO_RECURRENCERANGE_NUMBERED,
+			},
+			&dueAt,
+		},
+		{Recurrence{PatternType: TODO_RECURRENCE_DAILY}, &noDueAt},
+	} {
+		_, _, err := ConvRecurrenceToTW(&tc.recurrence, tc.dueAt)
+		assert.Error(t, err)
+	}
+}
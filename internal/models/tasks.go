@@ -3,6 +3,9 @@ package models
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"unicode"
 )
 
 type TaskStatus int
@@ -14,12 +17,34 @@ const (
 	// Taskwarrior User Defined Attribute (UDA): Microsoft To-Do List ID as received from the
 	// API
 	UDANameTodoListID string = "ms_todo_listid"
+	// Taskwarrior User Defined Attribute (UDA): Microsoft To-Do Checklist Item ID as
+	// received from the API
+	UDANameTodoChecklistItemID string = "ms_todo_checklistitemid"
 
-	TODO_TASKSTATUS_NOTSTARTED string = "notStarted"
+	TODO_TASKSTATUS_NOTSTARTED      string = "notStarted"
+	TODO_TASKSTATUS_INPROGRESS      string = "inProgress"
+	TODO_TASKSTATUS_COMPLETED       string = "completed"
+	TODO_TASKSTATUS_WAITINGONOTHERS string = "waitingOnOthers"
+	TODO_TASKSTATUS_DEFERRED        string = "deferred"
 
+	TODO_IMPORTANCE_LOW    string = "low"
+	TODO_IMPORTANCE_NORMAL string = "normal"
+	TODO_IMPORTANCE_HIGH   string = "high"
+
+	// Date time format of MS To-Do, example: 2022-08-02T00:00:00.0000000
+	TODO_DATETIME_FORMAT string = "2006-01-02T15:04:05.0000000"
+	// Date time format of Taskwarrior, example: 20220802T000000Z
+	TW_DATETIME_FORMAT string = "20060102T150405Z"
+)
+
+const (
 	TW_TASKSTATUS_PENDING TaskStatus = iota
 	TW_TASKSTATUS_COMPLETED
 	TW_TASKSTATUS_DELETED
+	// A pending task with a 'start' date.
+	TW_TASKSTATUS_STARTED
+	// A task with a 'wait' date in the future.
+	TW_TASKSTATUS_WAITING
 )
 
 type Task struct {
@@ -28,7 +53,35 @@ type Task struct {
 	Title      *string
 	// Format is yyyy-MM-DDThh:mm:ss, example: 2022-08-02T00:00:00.0000000
 	CompletedAt *string
-	Status      TaskStatus
+	// Format is yyyy-MM-DDThh:mm:ss in UTC, example: 2022-08-02T00:00:00.0000000
+	DueAt *string
+	// Importance as in MS To-Do: 'low', 'normal' or 'high'
+	Importance *string
+	// Body of a MS To-Do task as plain text. 'nil' if the body is left unchanged by an
+	// update of the MS To-Do task.
+	Note *string
+	// 'nil' if the checklist items are not synced.
+	ChecklistItems []ChecklistItem
+	// MS To-Do categories that are synced with Taskwarrior tags. 'nil' if the categories
+	// are not synced.
+	Categories []string
+	// 'nil' if the task is not recurring.
+	Recurrence *Recurrence
+	Status     TaskStatus
+	// Status of the MS To-Do task, 'nil' if it is not known. It tells 'waitingOnOthers'
+	// and 'deferred' apart, which are both 'waiting' in Taskwarrior.
+	ToDoStatus *string
+	// Point in time of the last modification: 'modified' of a Taskwarrior task or
+	// 'lastModifiedDateTime' of a MS To-Do task.
+	ModifiedAt *time.Time
+}
+
+// ChecklistItem is a step of a MS To-Do task. In Taskwarrior, it is a separate task
+// the parent task depends on.
+type ChecklistItem struct {
+	ToDoChecklistItemID *string
+	Title               *string
+	IsChecked           bool
 }
 
 type TaskwarriorTask struct {
@@ -49,6 +102,11 @@ func (this *Task) IsUpToDate(that *Task) bool {
 
 	if *this.Title == *that.Title &&
 		*this.CompletedAt == *that.CompletedAt &&
+		equalOptional(this.DueAt, that.DueAt) &&
+		equalOptional(this.Importance, that.Importance) &&
+		equalOptional(this.Note, that.Note) &&
+		equalChecklistItems(this.ChecklistItems, that.ChecklistItems) &&
+		equalCategories(this.Categories, that.Categories) &&
 		this.Status == that.Status {
 		return true
 	}
@@ -56,6 +114,88 @@ func (this *Task) IsUpToDate(that *Task) bool {
 	return false
 }
 
+// equalChecklistItems compares two lists of checklist items ignoring their order. If
+// one of the lists is 'nil', the checklist items are not synced and considered equal.
+func equalChecklistItems(this []ChecklistItem, that []ChecklistItem) bool {
+	if this == nil || that == nil {
+		return true
+	}
+	if len(this) != len(that) {
+		return false
+	}
+
+	thatItems := map[string]ChecklistItem{}
+	for _, item := range that {
+		thatItems[*item.ToDoChecklistItemID] = item
+	}
+	for _, item := range this {
+		thatItem, ok := thatItems[*item.ToDoChecklistItemID]
+		if !ok ||
+			!equalOptional(item.Title, thatItem.Title) ||
+			item.IsChecked != thatItem.IsChecked {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalCategories compares two lists of categories by their normalized tag names
+// ignoring their order. If one of the lists is 'nil', the categories are not synced and
+// considered equal.
+func equalCategories(this []string, that []string) bool {
+	if this == nil || that == nil {
+		return true
+	}
+
+	thisTags := map[string]bool{}
+	for _, category := range this {
+		thisTags[NormalizeTag(category)] = true
+	}
+	thatTags := map[string]bool{}
+	for _, category := range that {
+		thatTags[NormalizeTag(category)] = true
+	}
+	if len(thisTags) != len(thatTags) {
+		return false
+	}
+	for tag := range thisTags {
+		if !thatTags[tag] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NormalizeTag converts a MS To-Do category into a valid Taskwarrior tag: Whitespace is
+// replaced by '_' and all characters except letters, digits, '_', '-' and '.' are
+// removed. A tag must not start with '-' or '.'.
+func NormalizeTag(category string) string {
+	tag := strings.Join(strings.Fields(category), "_")
+	tag = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return -1
+	}, tag)
+	return strings.TrimLeft(tag, "-.")
+}
+
+// equalOptional compares two optional values. A value that is not set is equal to an
+// empty string.
+func equalOptional(this *string, that *string) bool {
+	thisValue := ""
+	if this != nil {
+		thisValue = *this
+	}
+	thatValue := ""
+	if that != nil {
+		thatValue = *that
+	}
+	return thisValue == thatValue
+}
+
 func ConvStatusFromToDo(todoStatus *string) (TaskStatus, error) {
 	if todoStatus == nil || *todoStatus == "" {
 		return -1, errors.New("[ConvStatusFromToDo] Failed to convert status. " +
@@ -65,12 +205,40 @@ func ConvStatusFromToDo(todoStatus *string) (TaskStatus, error) {
 	switch *todoStatus {
 	case TODO_TASKSTATUS_NOTSTARTED:
 		return TW_TASKSTATUS_PENDING, nil
+	case TODO_TASKSTATUS_INPROGRESS:
+		return TW_TASKSTATUS_STARTED, nil
+	case TODO_TASKSTATUS_COMPLETED:
+		return TW_TASKSTATUS_COMPLETED, nil
+	case TODO_TASKSTATUS_WAITINGONOTHERS, TODO_TASKSTATUS_DEFERRED:
+		return TW_TASKSTATUS_WAITING, nil
 	}
 
 	return -1, errors.New(fmt.Sprintf("[ConvStatusFromToDo] Failed to convert status. "+
 		"Status '%s' is unknown.", *todoStatus))
 }
 
+// ConvStatusToToDo converts a Taskwarrior status into the corresponding MS To-Do
+// status. A waiting task keeps the MS To-Do status 'toDoStatus' if it is
+// 'waitingOnOthers' or 'deferred', otherwise it is 'waitingOnOthers'.
+func ConvStatusToToDo(twStatus TaskStatus, toDoStatus *string) (string, error) {
+	switch twStatus {
+	case TW_TASKSTATUS_PENDING:
+		return TODO_TASKSTATUS_NOTSTARTED, nil
+	case TW_TASKSTATUS_STARTED:
+		return TODO_TASKSTATUS_INPROGRESS, nil
+	case TW_TASKSTATUS_COMPLETED:
+		return TODO_TASKSTATUS_COMPLETED, nil
+	case TW_TASKSTATUS_WAITING:
+		if toDoStatus != nil && *toDoStatus == TODO_TASKSTATUS_DEFERRED {
+			return TODO_TASKSTATUS_DEFERRED, nil
+		}
+		return TODO_TASKSTATUS_WAITINGONOTHERS, nil
+	}
+
+	return "", errors.New(fmt.Sprintf("[ConvStatusToToDo] Failed to convert status. "+
+		"Status '%v' has no counterpart in MS To-Do.", twStatus))
+}
+
 func ConvStatusFromTW(twStatus *string) (TaskStatus, error) {
 	if twStatus == nil || *twStatus == "" {
 		return -1, errors.New("[ConvStatusFromTW] Failed to convert status. " +
@@ -84,8 +252,44 @@ func ConvStatusFromTW(twStatus *string) (TaskStatus, error) {
 		return TW_TASKSTATUS_COMPLETED, nil
 	case "deleted":
 		return TW_TASKSTATUS_DELETED, nil
+	case "waiting":
+		return TW_TASKSTATUS_WAITING, nil
 	}
 
 	return -1, errors.New(fmt.Sprintf("[ConvStatusFromTW] Failed to convert status. "+
 		"Status '%s' is unknown.", *twStatus))
 }
+
+// ConvDateTimeFromTW converts a Taskwarrior date time, for example the 'end' of a task,
+// into the MS To-Do date time format. Taskwarrior date times are always in UTC.
+func ConvDateTimeFromTW(twDateTime *string) (string, error) {
+	if twDateTime == nil || *twDateTime == "" {
+		return "", errors.New("[ConvDateTimeFromTW] Failed to convert date time. " +
+			"Date time is 'nil' or empty.")
+	}
+
+	dateTime, err := time.Parse(TW_DATETIME_FORMAT, *twDateTime)
+	if err != nil {
+		return "", fmt.Errorf("[ConvDateTimeFromTW] Failed to convert date time "+
+			"'%s': %w", *twDateTime, err)
+	}
+
+	return dateTime.Format(TODO_DATETIME_FORMAT), nil
+}
+
+// ConvDateTimeToTW converts a MS To-Do date time in UTC into the Taskwarrior date time
+// format.
+func ConvDateTimeToTW(todoDateTime *string) (string, error) {
+	if todoDateTime == nil || *todoDateTime == "" {
+		return "", errors.New("[ConvDateTimeToTW] Failed to convert date time. " +
+			"Date time is 'nil' or empty.")
+	}
+
+	dateTime, err := time.Parse(TODO_DATETIME_FORMAT, *todoDateTime)
+	if err != nil {
+		return "", fmt.Errorf("[ConvDateTimeToTW] Failed to convert date time "+
+			"'%s': %w", *todoDateTime, err)
+	}
+
+	return dateTime.Format(TW_DATETIME_FORMAT), nil
+}
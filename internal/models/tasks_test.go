@@ -0,0 +1,161 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvDateTimeFromTW_isOK(t *testing.T) {
+	twDateTime := "20220802T134501Z"
+
+	dateTime, err := ConvDateTimeFromTW(&twDateTime)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-08-02T13:45:01.0000000", dateTime)
+}
+
+func TestConvDateTimeFromTW_invalid_isError(t *testing.T) {
+	twDateTime := "2022-08-02"
+
+	_, err := ConvDateTimeFromTW(&twDateTime)
+
+	assert.Error(t, err)
+}
+
+func TestTaskStatus_zeroValue_isPending(t *testing.T) {
+	var task Task
+
+	assert.Equal(t, TW_TASKSTATUS_PENDING, task.Status)
+}
+
+func TestConvStatusFromToDo_completed_isCompleted(t *testing.T) {
+	todoStatus := TODO_TASKSTATUS_COMPLETED
+
+	status, err := ConvStatusFromToDo(&todoStatus)
+
+	assert.NoError(t, err)
+	assert.Equal(t, TW_TASKSTATUS_COMPLETED, status)
+}
+
+func TestConvStatusFromToDo_allStatuses_isOK(t *testing.T) {
+	expected := map[string]TaskStatus{
+		TODO_TASKSTATUS_NOTSTARTED:      TW_TASKSTATUS_PENDING,
+		TODO_TASKSTATUS_INPROGRESS:      TW_TASKSTATUS_STARTED,
+		TODO_TASKSTATUS_COMPLETED:       TW_TASKSTATUS_COMPLETED,
+		TODO_TASKSTATUS_WAITINGONOTHERS: TW_TASKSTATUS_WAITING,
+		TODO_TASKSTATUS_DEFERRED:        TW_TASKSTATUS_WAITING,
+	}
+
+	for todoStatus, twStatus := range expected {
+		status, err := ConvStatusFromToDo(&todoStatus)
+		assert.NoError(t, err)
+		assert.Equal(t, twStatus, status, todoStatus)
+	}
+}
+
+func TestConvStatusToToDo_deleted_isError(t *testing.T) {
+	_, err := ConvStatusToToDo(TW_TASKSTATUS_DELETED, nil)
+
+	assert.Error(t, err)
+}
+
+func TestConvStatusToToDo_waiting_keepsToDoStatus(t *testing.T) {
+	deferred := TODO_TASKSTATUS_DEFERRED
+	notStarted := TODO_TASKSTATUS_NOTSTARTED
+
+	status, err := ConvStatusToToDo(TW_TASKSTATUS_WAITING, &deferred)
+	assert.NoError(t, err)
+	assert.Equal(t, TODO_TASKSTATUS_DEFERRED, status)
+
+	status, err = ConvStatusToToDo(TW_TASKSTATUS_WAITING, &notStarted)
+	assert.NoError(t, err)
+	assert.Equal(t, TODO_TASKSTATUS_WAITINGONOTHERS, status)
+
+	status, err = ConvStatusToToDo(TW_TASKSTATUS_WAITING, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TODO_TASKSTATUS_WAITINGONOTHERS, status)
+
+	status, err = ConvStatusToToDo(TW_TASKSTATUS_PENDING, &deferred)
+	assert.NoError(t, err)
+	assert.Equal(t, TODO_TASKSTATUS_NOTSTARTED, status)
+}
+
+func TestConvDateTimeToTW_isOK(t *testing.T) {
+	todoDateTime := "2022-08-02T13:45:01.0000000"
+
+	dateTime, err := ConvDateTimeToTW(&todoDateTime)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "20220802T134501Z", dateTime)
+}
+
+func TestIsUpToDate_dueChanged_isFalse(t *testing.T) {
+	title := "foo"
+	completedAt := ""
+	dueA := "2022-08-02T00:00:00.0000000"
+	dueB := "2022-08-03T00:00:00.0000000"
+	taskA := &Task{Title: &title, CompletedAt: &completedAt, DueAt: &dueA}
+	taskB := &Task{Title: &title, CompletedAt: &completedAt, DueAt: &dueB}
+	taskNoDue := &Task{Title: &title, CompletedAt: &completedAt}
+
+	assert.False(t, taskA.IsUpToDate(taskB))
+	assert.False(t, taskA.IsUpToDate(taskNoDue))
+	assert.True(t, taskA.IsUpToDate(&Task{Title: &title, CompletedAt: &completedAt, DueAt: &dueA}))
+}
+
+func TestIsUpToDate_checklistItems(t *testing.T) {
+	title := "foo"
+	completedAt := ""
+	itemIDA := "a"
+	itemIDB := "b"
+	itemTitle := "bar"
+	items := []ChecklistItem{
+		{ToDoChecklistItemID: &itemIDA, Title: &itemTitle, IsChecked: true},
+		{ToDoChecklistItemID: &itemIDB, Title: &itemTitle, IsChecked: false},
+	}
+	reorderedItems := []ChecklistItem{items[1], items[0]}
+	uncheckedItems := []ChecklistItem{
+		{ToDoChecklistItemID: &itemIDA, Title: &itemTitle, IsChecked: false},
+		items[1],
+	}
+	task := &Task{Title: &title, CompletedAt: &completedAt, ChecklistItems: items}
+
+	assert.True(t, task.IsUpToDate(
+		&Task{Title: &title, CompletedAt: &completedAt, ChecklistItems: reorderedItems}))
+	assert.True(t, task.IsUpToDate(
+		&Task{Title: &title, CompletedAt: &completedAt}))
+	assert.False(t, task.IsUpToDate(
+		&Task{Title: &title, CompletedAt: &completedAt, ChecklistItems: uncheckedItems}))
+	assert.False(t, task.IsUpToDate(
+		&Task{Title: &title, CompletedAt: &completedAt, ChecklistItems: items[:1]}))
+}
+
+func TestNormalizeTag(t *testing.T) {
+	assert.Equal(t, "Red_category", NormalizeTag(" Red  category "))
+	assert.Equal(t, "work.meeting", NormalizeTag("work.meeting"))
+	assert.Equal(t, "ToDo", NormalizeTag("-To:Do!"))
+	assert.Equal(t, "Büro", NormalizeTag("Büro"))
+}
+
+func TestIsUpToDate_categories(t *testing.T) {
+	title := "foo"
+	completedAt := ""
+	task := &Task{
+		Title:       &title,
+		CompletedAt: &completedAt,
+		Categories:  []string{"Red category", "work"},
+	}
+
+	assert.True(t, task.IsUpToDate(&Task{
+		Title:       &title,
+		CompletedAt: &completedAt,
+		Categories:  []string{"work", "Red_category"},
+	}))
+	assert.True(t, task.IsUpToDate(&Task{Title: &title, CompletedAt: &completedAt}))
+	assert.False(t, task.IsUpToDate(&Task{
+		Title:       &title,
+		CompletedAt: &completedAt,
+		Categories:  []string{"work"},
+	}))
+}
@@ -1,61 +1,142 @@
 package mstodo
 
 import (
-	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
-	azidentity "github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoft/kiota-abstractions-go/authentication"
 	a "github.com/microsoft/kiota-authentication-azure-go"
+	khttp "github.com/microsoft/kiota-http-go"
 
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphgocore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	graphlists "github.com/microsoftgraph/msgraph-sdk-go/me/todo/lists"
 	graphconfig "github.com/microsoftgraph/msgraph-sdk-go/me/todo/lists/item/tasks"
+	graphtaskconfig "github.com/microsoftgraph/msgraph-sdk-go/me/todo/lists/item/tasks/item"
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 
 	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
 )
 
-var authenticatedGraphClient *GraphClient
+// expandChecklistItems is the '$expand' query parameter that includes the checklist
+// items into the task data.
+var expandChecklistItems = []string{"checklistItems"}
 
 type ClientFactory struct {
 	// Using functions is required as Viper parses the config not before a command's
 	// Execute() function is called.
-	GetTenantID func() string
-	GetClientID func() string
+	// Credentials of each account of the credentials.yaml. Key is the account name.
+	GetAccounts func() (map[string]Credentials, error)
+	// Path of the file the tokens of an account are cached in.
+	GetTokenCachePath func(account string) (string, error)
+
+	mutex sync.Mutex
+	// Authenticated client of each account. Key is the account name.
+	authenticatedClients map[string]*GraphClient
 }
 
 type ClientFacade interface {
-	ReadOpenTasks(listID *string) (*[]models.Task, error)
+	ReadOpenTasks(listID *string) (*[]models.Task, map[string]error, int, error)
 	ReadTaskByID(listID *string, taskID *string) (*models.Task, error)
+	ReadTasksByIDs(
+		listID *string,
+		taskIDs []string,
+	) (map[string]*models.Task, map[string]error, error)
+	CreateTask(listID *string, task *models.Task) (*models.Task, error)
+	UpdateTask(task *models.Task) error
+	ReadLists() (*[]models.TaskList, error)
+	CreateList(displayName *string) (*models.TaskList, error)
+	ReadTasksDelta(listID *string, deltaLink *string) (*TasksDelta, error)
+	ReadThrottleStatistics() ThrottleStatistics
 }
 
 type GraphClient struct {
 	authenticatedClient *msgraphsdk.GraphServiceClient
+	// Request adapter of the client to follow the links returned by the API.
+	adapter *msgraphsdk.GraphRequestAdapter
+	// Middleware of the client that retries requests after transient errors.
+	throttle *throttleHandler
+	// Path of the user whose tasks are accessed, '/me' or '/users/<userID>'.
+	userPath string
 }
 
-// Get returns a singleton instance of a Microsoft Graph client using the Device Code
-// Authentication Provider.
-func (fact *ClientFactory) GetGraphClient() (*GraphClient,
-	error,
-) {
-	if authenticatedGraphClient != nil {
-		return authenticatedGraphClient, nil
-	}
-
-	tenantID := fact.GetTenantID()
-	clientID := fact.GetClientID()
+// graphClientOptions are the options of the Microsoft Graph SDK for its middlewares.
+var graphClientOptions = msgraphsdk.GetDefaultClientOptions()
 
-	if tenantID == "" || clientID == "" {
+// GetGraphClients returns an authenticated Microsoft Graph client for each account of
+// the credentials.yaml. Key is the account name.
+func (fact *ClientFactory) GetGraphClients() (map[string]ClientFacade, error) {
+	accounts, err := fact.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
 		return nil, errors.New(
-			"[AzureAuth] Empty Azure Tenant ID and/or Client ID. Check the credentials.yaml file.",
+			"[AzureAuth] No account configured. Check the credentials.yaml file.",
 		)
 	}
 
-	client, err := authenticate(tenantID, clientID)
+	// Sort the accounts such that the user is asked to sign in in a stable order.
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	clients := map[string]ClientFacade{}
+	for _, name := range names {
+		creds := accounts[name]
+		client, err := fact.GetGraphClient(name, &creds)
+		if err != nil {
+			return nil, err
+		}
+		clients[name] = client
+	}
+	return clients, nil
+}
+
+// GetGraphClient returns a singleton instance of a Microsoft Graph client per account
+// using the auth flow of the credentials of the account.
+func (fact *ClientFactory) GetGraphClient(
+	account string,
+	creds *Credentials,
+) (*GraphClient, error) {
+	fact.mutex.Lock()
+	defer fact.mutex.Unlock()
+
+	if authenticatedClient, ok := fact.authenticatedClients[account]; ok {
+		return authenticatedClient, nil
+	}
+
+	err := ValidateAccountName(account)
 	if err != nil {
 		return nil, err
 	}
+	err = creds.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("[AzureAuth] Account '%s': %w", account, err)
+	}
 
-	me, err := client.Me().Get()
+	tokenCachePath, err := fact.GetTokenCachePath(account)
+	if err != nil {
+		return nil, fmt.Errorf("[AzureAuth] Failed to determine token cache file: %w", err)
+	}
+
+	fmt.Printf("[AzureAuth] Authenticating account '%s'...\n", account)
+	authenticatedClient, err := authenticate(creds, tokenCachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	me, err := authenticatedClient.authenticatedClient.Me().Get()
 	if err != nil {
 		return nil, fmt.Errorf(
 			"[AzureAuth] Failed to retrieve data about authenticated user: %v",
@@ -63,9 +144,17 @@ func (fact *ClientFactory) GetGraphClient() (*GraphClient,
 		)
 	}
 
-	fmt.Printf("[AzureAuth] Authenticated as %s\n", *me.GetDisplayName())
+	fmt.Printf(
+		"[AzureAuth] Account '%s' authenticated as %s\n",
+		account,
+		*me.GetDisplayName(),
+	)
+
+	if fact.authenticatedClients == nil {
+		fact.authenticatedClients = map[string]*GraphClient{}
+	}
+	fact.authenticatedClients[account] = authenticatedClient
 
-	authenticatedClient := &GraphClient{authenticatedClient: client}
 	return authenticatedClient, nil
 }
 
@@ -75,11 +164,17 @@ func (graph GraphClient) ReadTaskByID(
 	listID *string,
 	taskID *string,
 ) (*models.Task, error) {
+	reqConf := &graphtaskconfig.TodoTaskItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &graphtaskconfig.TodoTaskItemRequestBuilderGetQueryParameters{
+			Expand: expandChecklistItems,
+		},
+	}
+
 	taskData, err := graph.authenticatedClient.Me().
 		Todo().
 		ListsById(*listID).
 		TasksById(*taskID).
-		Get()
+		GetWithRequestConfigurationAndResponseHandler(reqConf, nil)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"[ReadTaskByID] Failed to fetch the task with ID '%s' from To-Do list "+
@@ -103,108 +198,567 @@ func (graph GraphClient) ReadTaskByID(
 		*taskData.GetTitle(),
 	)
 
-	completedAt := ""
-	if taskData.GetCompletedDateTime() != nil {
-		completedAt = *taskData.GetCompletedDateTime().GetDateTime()
+	return convTask(listID, taskData)
+}
+
+// ReadOpenTasks uses the Microsoft Graph API to fetch the To-Do tasks that are not
+// 'completed'. The tasks are fetched in pages of the configured page size. The number of
+// fetched pages is returned. A task that fails to be converted is returned in the
+// errors by its task ID and does not affect the other tasks.
+func (graph GraphClient) ReadOpenTasks(
+	listID *string,
+) (*[]models.Task, map[string]error, int, error) {
+	openTasksFilter := fmt.Sprintf("status ne '%s'", models.TODO_TASKSTATUS_COMPLETED)
+	reqParams := &graphconfig.TasksRequestBuilderGetQueryParameters{
+		Filter: &openTasksFilter,
+		Expand: expandChecklistItems,
+	}
+	if config.PageSize > 0 {
+		reqParams.Top = &config.PageSize
+	}
+	reqConf := &graphconfig.TasksRequestBuilderGetRequestConfiguration{
+		QueryParameters: reqParams,
+	}
+
+	requestBuilder := graph.authenticatedClient.Me().
+		Todo().
+		ListsById(*listID).
+		Tasks()
+
+	tasks := []models.Task{}
+	taskErrs := map[string]error{}
+	pageCount := 0
+	for {
+		tasksResponse, err := requestBuilder.
+			GetWithRequestConfigurationAndResponseHandler(reqConf, nil)
+		if err != nil {
+			return nil, nil, pageCount, fmt.Errorf(
+				"[ReadOpenTasks] Failed to fetch the tasks of To-Do list '%s': %w\n",
+				*listID,
+				err,
+			)
+		}
+		pageCount++
+
+		for _, taskData := range tasksResponse.GetValue() {
+			task, err := convTask(listID, taskData)
+			if err != nil {
+				taskErrs[*taskData.GetId()] = err
+				continue
+			}
+			tasks = append(tasks, *task)
+		}
+
+		// The next link already carries the query parameters.
+		nextLink := tasksResponse.GetOdatanextLink()
+		if nextLink == nil || *nextLink == "" {
+			break
+		}
+		requestBuilder = graphconfig.NewTasksRequestBuilder(*nextLink, graph.adapter)
+		reqConf = nil
+	}
+
+	fmt.Printf(
+		"[ReadOpenTasks] %v tasks fetched in %v pages, %v failed.\n",
+		len(tasks),
+		pageCount,
+		len(taskErrs),
+	)
+
+	return &tasks, taskErrs, pageCount, nil
+}
+
+// convTask converts the task data received from the Microsoft Graph API into a task.
+func convTask(listID *string, taskData graphmodels.TodoTaskable) (*models.Task, error) {
+	completedAt, err := convDateTime(taskData.GetCompletedDateTime())
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[convTask] Task with ID '%s': Failed to parse completion date:\n%w\n",
+			*taskData.GetId(),
+			err,
+		)
+	}
+
+	dueAt, err := convDateTime(taskData.GetDueDateTime())
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[convTask] Task with ID '%s': Failed to parse due date:\n%w\n",
+			*taskData.GetId(),
+			err,
+		)
 	}
 
 	todoTaskStatus := taskData.GetStatus().String()
 	taskStatus, err := models.ConvStatusFromToDo(&todoTaskStatus)
 	if err != nil {
 		return nil, fmt.Errorf(
-			"[ReadTaskByID] Task with ID '%s': Failed to parse task status '%s':\n%w\n",
-			*taskID,
+			"[convTask] Task with ID '%s': Failed to parse task status '%s':\n%w\n",
+			*taskData.GetId(),
 			todoTaskStatus,
 			err,
 		)
 	}
 
+	importance := models.TODO_IMPORTANCE_NORMAL
+	if taskData.GetImportance() != nil {
+		importance = taskData.GetImportance().String()
+	}
+
+	note := ""
+	if body := taskData.GetBody(); body != nil && body.GetContent() != nil {
+		if body.GetContentType() != nil &&
+			*body.GetContentType() == graphmodels.HTML_BODYTYPE {
+			note = htmlToText(*body.GetContent())
+		} else {
+			note = normalizeText(*body.GetContent())
+		}
+	}
+
+	categories := []string{}
+	if taskData.GetCategories() != nil {
+		categories = taskData.GetCategories()
+	}
+
+	// The checklist items are only included if they are expanded. 'nil' marks them as not
+	// synced.
+	var checklistItems []models.ChecklistItem
+	if taskData.GetChecklistItems() != nil {
+		checklistItems = []models.ChecklistItem{}
+	}
+	for _, itemData := range taskData.GetChecklistItems() {
+		isChecked := itemData.GetIsChecked() != nil && *itemData.GetIsChecked()
+		itemTitle := ""
+		if itemData.GetDisplayName() != nil {
+			itemTitle = *itemData.GetDisplayName()
+		}
+		checklistItems = append(checklistItems, models.ChecklistItem{
+			ToDoChecklistItemID: itemData.GetId(),
+			Title:               &itemTitle,
+			IsChecked:           isChecked,
+		})
+	}
+
+	recurrence := convRecurrence(taskData.GetRecurrence())
+
 	return &models.Task{
-		ToDoTaskID:  taskData.GetId(),
-		ToDoListID:  listID,
-		Title:       taskData.GetTitle(),
-		CompletedAt: &completedAt,
-		Status:      taskStatus,
+		ToDoTaskID:     taskData.GetId(),
+		ToDoListID:     listID,
+		Title:          taskData.GetTitle(),
+		CompletedAt:    &completedAt,
+		DueAt:          &dueAt,
+		Importance:     &importance,
+		Note:           &note,
+		ChecklistItems: checklistItems,
+		Categories:     categories,
+		Recurrence:     recurrence,
+		Status:         taskStatus,
+		ToDoStatus:     &todoTaskStatus,
+		ModifiedAt:     taskData.GetLastModifiedDateTime(),
 	}, nil
 }
 
-// ReadOpenTasks uses the Microsoft Graph API to fetch the To-Do tasks with status
-// 'notStarted'.
-func (graph GraphClient) ReadOpenTasks(
-	listID *string,
-) (*[]models.Task, error) {
-	openTasksFilter := fmt.Sprintf("status eq '%s'", models.TODO_TASKSTATUS_NOTSTARTED)
-	reqParams := &graphconfig.TasksRequestBuilderGetQueryParameters{
-		Filter: &openTasksFilter,
+// convRecurrence converts the recurrence received from the Microsoft Graph API. 'nil' is
+// returned if the task is not recurring.
+func convRecurrence(recurrenceData graphmodels.PatternedRecurrenceable) *models.Recurrence {
+	if recurrenceData == nil || recurrenceData.GetPattern() == nil {
+		return nil
 	}
-	reqConf := &graphconfig.TasksRequestBuilderGetRequestConfiguration{
-		QueryParameters: reqParams,
+
+	recurrence := &models.Recurrence{
+		DaysOfWeek: recurrenceData.GetPattern().GetDaysOfWeek(),
+		RangeType:  models.TODO_RECURRENCERANGE_NOEND,
+	}
+	if recurrenceData.GetPattern().GetType() != nil {
+		recurrence.PatternType = recurrenceData.GetPattern().GetType().String()
+	}
+	if recurrenceData.GetPattern().GetInterval() != nil {
+		recurrence.Interval = *recurrenceData.GetPattern().GetInterval()
+	}
+	if recurrenceRange := recurrenceData.GetRange(); recurrenceRange != nil {
+		if recurrenceRange.GetType() != nil {
+			recurrence.RangeType = recurrenceRange.GetType().String()
+		}
+		if recurrenceRange.GetEndDate() != nil {
+			recurrence.EndDate = recurrenceRange.GetEndDate().String()
+		}
+	}
+
+	return recurrence
+}
+
+// preferUTC is the preference that makes the Microsoft Graph API return the date times
+// in UTC instead of a Windows time zone like 'W. Europe Standard Time', which the time
+// package does not know.
+const preferUTC = `outlook.timezone="UTC"`
+
+// timeZoneHandler is a middleware of the HTTP client that asks for the date times in
+// UTC by the header 'Prefer'.
+type timeZoneHandler struct{}
+
+// Intercept adds the preference for UTC to the request.
+func (handler *timeZoneHandler) Intercept(
+	pipeline khttp.Pipeline,
+	middlewareIndex int,
+	req *http.Request,
+) (*http.Response, error) {
+	req.Header.Add("Prefer", preferUTC)
+	return pipeline.Next(req, middlewareIndex)
+}
+
+// convDateTime converts a date time with time zone received from the Microsoft Graph
+// API into UTC. An empty string is returned if the date time is not set.
+func convDateTime(dateTimeTimeZone graphmodels.DateTimeTimeZoneable) (string, error) {
+	if dateTimeTimeZone == nil || dateTimeTimeZone.GetDateTime() == nil {
+		return "", nil
+	}
+
+	location := time.UTC
+	if timeZone := dateTimeTimeZone.GetTimeZone(); timeZone != nil && *timeZone != "" {
+		var err error
+		location, err = time.LoadLocation(*timeZone)
+		if err != nil {
+			return "", fmt.Errorf("[convDateTime] Unknown time zone '%s': %w", *timeZone, err)
+		}
+	}
+
+	dateTime, err := time.ParseInLocation(
+		models.TODO_DATETIME_FORMAT,
+		*dateTimeTimeZone.GetDateTime(),
+		location,
+	)
+	if err != nil {
+		return "", fmt.Errorf(
+			"[convDateTime] Failed to parse date time '%s': %w",
+			*dateTimeTimeZone.GetDateTime(),
+			err,
+		)
+	}
+
+	return dateTime.UTC().Format(models.TODO_DATETIME_FORMAT), nil
+}
+
+// convDateTimeData converts a date time in UTC into the date time with time zone
+// expected by the Microsoft Graph API.
+func convDateTimeData(dateTime *string) graphmodels.DateTimeTimeZoneable {
+	dateTimeTimeZone := graphmodels.NewDateTimeTimeZone()
+	dateTimeTimeZone.SetDateTime(dateTime)
+	timeZone := "UTC"
+	dateTimeTimeZone.SetTimeZone(&timeZone)
+	return dateTimeTimeZone
+}
+
+// convTaskData converts a task into the task data expected by the Microsoft Graph API.
+func convTaskData(task *models.Task) (graphmodels.TodoTaskable, error) {
+	todoTaskStatus, err := models.ConvStatusToToDo(task.Status, task.ToDoStatus)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[convTaskData] Task '%s': Failed to convert task status:\n%w\n",
+			*task.Title,
+			err,
+		)
+	}
+	status, err := graphmodels.ParseTaskStatus(todoTaskStatus)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[convTaskData] Task '%s': Failed to parse task status '%s':\n%w\n",
+			*task.Title,
+			todoTaskStatus,
+			err,
+		)
+	}
+
+	taskData := graphmodels.NewTodoTask()
+	taskData.SetTitle(task.Title)
+	taskData.SetStatus(status.(*graphmodels.TaskStatus))
+	if task.Status == models.TW_TASKSTATUS_COMPLETED {
+		taskData.SetCompletedDateTime(convDateTimeData(task.CompletedAt))
+	}
+	if task.DueAt != nil && *task.DueAt != "" {
+		taskData.SetDueDateTime(convDateTimeData(task.DueAt))
+	}
+	if task.Note != nil {
+		body := graphmodels.NewItemBody()
+		body.SetContent(task.Note)
+		contentType := graphmodels.TEXT_BODYTYPE
+		body.SetContentType(&contentType)
+		taskData.SetBody(body)
+	}
+	if task.Importance != nil && *task.Importance != "" {
+		importance, err := graphmodels.ParseImportance(*task.Importance)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"[convTaskData] Task '%s': Failed to parse importance '%s':\n%w\n",
+				*task.Title,
+				*task.Importance,
+				err,
+			)
+		}
+		taskData.SetImportance(importance.(*graphmodels.Importance))
+	}
+	if task.Categories != nil {
+		taskData.SetCategories(task.Categories)
+	}
+
+	return taskData, nil
+}
+
+// CreateTask creates a task in the To-Do list given by a list ID. The returned task
+// carries the task ID assigned by MS To-Do.
+func (graph GraphClient) CreateTask(
+	listID *string,
+	task *models.Task,
+) (*models.Task, error) {
+	taskData, err := convTaskData(task)
+	if err != nil {
+		return nil, err
 	}
 
-	tasksResponse, err := graph.authenticatedClient.Me().
+	createdTask, err := graph.authenticatedClient.Me().
 		Todo().
 		ListsById(*listID).
 		Tasks().
-		GetWithRequestConfigurationAndResponseHandler(reqConf, nil)
+		Post(taskData)
 	if err != nil {
 		return nil, fmt.Errorf(
-			"[ReadOpenTasks] Failed to fetch the tasks of To-Do list '%s': %w\n",
+			"[CreateTask] Failed to create task '%s' in To-Do list '%s':\n%w\n",
+			*task.Title,
 			*listID,
 			err,
 		)
 	}
 
-	tasksRespVal := tasksResponse.GetValue()
 	fmt.Printf(
-		"[ReadOpenTasks] %v tasks fetched.\n",
-		len(tasksRespVal),
+		"[CreateTask] Task created: '%s'\n",
+		*createdTask.GetTitle(),
 	)
 
-	var tasks []models.Task
-	for _, task := range tasksRespVal {
-		tasks = append(tasks, models.Task{
-			ToDoListID: listID,
-			ToDoTaskID: task.GetId(),
-			Title:      task.GetTitle(),
-		})
-	}
-	return &tasks, nil
+	return convTask(listID, createdTask)
 }
 
-func authenticate(
-	tenantID string,
-	clientID string,
-) (*msgraphsdk.GraphServiceClient, error) {
-	cred, err := azidentity.NewDeviceCodeCredential(
-		&azidentity.DeviceCodeCredentialOptions{
-			TenantID: tenantID,
-			ClientID: clientID,
-			UserPrompt: func(ctx context.Context, message azidentity.DeviceCodeMessage) error {
-				fmt.Println(message.Message)
-				return nil
-			},
+// UpdateTask transfers the title, the status, the due date, the importance and the
+// note of a task to MS To-Do. The completion date time is expected in UTC.
+func (graph GraphClient) UpdateTask(task *models.Task) error {
+	taskData, err := convTaskData(task)
+	if err != nil {
+		return err
+	}
+
+	requestInfo, err := graph.authenticatedClient.Me().
+		Todo().
+		ListsById(*task.ToDoListID).
+		TasksById(*task.ToDoTaskID).
+		CreatePatchRequestInformation(taskData)
+	if err != nil {
+		return fmt.Errorf("[UpdateTask] Failed to create the request: %w\n", err)
+	}
+	// A due date removed in Taskwarrior has to be cleared explicitly, a PATCH without
+	// 'dueDateTime' keeps the due date in MS To-Do.
+	if task.DueAt == nil || *task.DueAt == "" {
+		requestInfo.Content, err = withNullProperty(requestInfo.Content, "dueDateTime")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = graph.adapter.SendNoContentAsync(
+		requestInfo,
+		nil,
+		abstractions.ErrorMappings{
+			"4XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+			"5XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
 		},
 	)
+	if err != nil {
+		return fmt.Errorf(
+			"[UpdateTask] Failed to update the task with ID '%s' in To-Do list "+
+				"'%s':\n%w\n",
+			*task.ToDoTaskID,
+			*task.ToDoListID,
+			err,
+		)
+	}
+
+	fmt.Printf("[UpdateTask] Task updated: '%s'\n", *task.Title)
+
+	return nil
+}
+
+// withNullProperty adds the property with the value 'null' to the JSON object of a
+// request body. The serializer of the Microsoft Graph SDK omits properties that are not
+// set and cannot write 'null'.
+func withNullProperty(content []byte, property string) ([]byte, error) {
+	body := map[string]json.RawMessage{}
+	err := json.Unmarshal(content, &body)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[withNullProperty] Failed to unmarshall request body: %w\n",
+			err,
+		)
+	}
+	body[property] = json.RawMessage("null")
+
+	content, err = json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[withNullProperty] Failed to marshall request body: %w\n",
+			err,
+		)
+	}
+	return content, nil
+}
+
+// ReadLists fetches the To-Do lists of the authenticated user. MS To-Do returns the
+// lists in pages, all pages are fetched.
+func (graph GraphClient) ReadLists() (*[]models.TaskList, error) {
+	requestBuilder := graph.authenticatedClient.Me().
+		Todo().
+		Lists()
+
+	lists := []models.TaskList{}
+	for {
+		listsResponse, err := requestBuilder.Get()
+		if err != nil {
+			return nil, fmt.Errorf("[ReadLists] Failed to fetch the To-Do lists: %w\n", err)
+		}
+
+		for _, listData := range listsResponse.GetValue() {
+			lists = append(lists, convTaskList(listData))
+		}
+
+		nextLink := listsResponse.GetOdatanextLink()
+		if nextLink == nil || *nextLink == "" {
+			break
+		}
+		requestBuilder = graphlists.NewListsRequestBuilder(*nextLink, graph.adapter)
+	}
+
+	fmt.Printf("[ReadLists] %v lists fetched.\n", len(lists))
+
+	return &lists, nil
+}
+
+// CreateList creates a To-Do list with the given display name.
+func (graph GraphClient) CreateList(displayName *string) (*models.TaskList, error) {
+	listData := graphmodels.NewTodoTaskList()
+	listData.SetDisplayName(displayName)
+
+	createdListData, err := graph.authenticatedClient.Me().
+		Todo().
+		Lists().
+		Post(listData)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[CreateList] Failed to create the To-Do list '%s': %w\n",
+			*displayName,
+			err,
+		)
+	}
+
+	fmt.Printf("[CreateList] List created: '%s'\n", *displayName)
+
+	list := convTaskList(createdListData)
+	return &list, nil
+}
+
+// ReadThrottleStatistics returns the counts of the requests that failed with a
+// transient error since the client has been created.
+func (graph GraphClient) ReadThrottleStatistics() ThrottleStatistics {
+	return graph.throttle.statistics()
+}
+
+// convTaskList converts the list data received from the Microsoft Graph API into a task
+// list.
+func convTaskList(listData graphmodels.TodoTaskListable) models.TaskList {
+	list := models.TaskList{
+		WellknownListName: models.TODO_WELLKNOWNLIST_NONE,
+	}
+	if listData.GetId() != nil {
+		list.ID = *listData.GetId()
+	}
+	if listData.GetDisplayName() != nil {
+		list.DisplayName = *listData.GetDisplayName()
+	}
+	if listData.GetWellknownListName() != nil {
+		list.WellknownListName = listData.GetWellknownListName().String()
+	}
+	if listData.GetIsOwner() != nil {
+		list.IsOwner = *listData.GetIsOwner()
+	}
+	if listData.GetIsShared() != nil {
+		list.IsShared = *listData.GetIsShared()
+	}
+	return list
+}
+
+func authenticate(
+	creds *Credentials,
+	tokenCachePath string,
+) (*GraphClient, error) {
+	cred, scopes, err := newCredential(creds, tokenCachePath)
 	if err != nil {
 		fmt.Printf("[AzureAuth] Error creating credentials: %v\n", err)
+		return nil, err
 	}
 
-	auth, err := a.NewAzureIdentityAuthenticationProviderWithScopes(
-		cred,
-		[]string{"Tasks.Read"},
-	)
+	auth, err := a.NewAzureIdentityAuthenticationProviderWithScopes(cred, scopes)
 	if err != nil {
 		fmt.Printf("[AzureAuth] Error authentication provider: %v\n", err)
 		return nil, err
 	}
 
-	adapter, err := msgraphsdk.NewGraphRequestAdapter(auth)
+	userID := ""
+	if !creds.isDelegated() {
+		userID = creds.UserID
+	}
+	return newGraphClient(auth, userID)
+}
+
+// NewGraphClient creates a client that authenticates its requests by the given
+// provider, for example a client of a fake To-Do server given by the configured base URL
+// with an anonymous provider.
+func NewGraphClient(auth authentication.AuthenticationProvider) (*GraphClient, error) {
+	return newGraphClient(auth, "")
+}
+
+// newGraphClient creates a client that authenticates its requests by the given
+// provider. Requests that fail with a transient error are retried. If a user ID is
+// given, the requests for the signed-in user are sent to that user.
+func newGraphClient(
+	auth authentication.AuthenticationProvider,
+	userID string,
+) (*GraphClient, error) {
+	throttle := newThrottleHandler()
+	middlewares := msgraphgocore.GetDefaultMiddlewaresWithOptions(&graphClientOptions)
+	for i, middleware := range middlewares {
+		if _, isRetryHandler := middleware.(*khttp.RetryHandler); isRetryHandler {
+			middlewares[i] = throttle
+		}
+	}
+	middlewares = append(middlewares, &timeZoneHandler{})
+	if userID != "" {
+		middlewares = append(middlewares, &userPathHandler{userID: userID})
+	}
+	httpClient := msgraphgocore.GetDefaultClient(&graphClientOptions, middlewares...)
+
+	adapter, err := msgraphsdk.
+		NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+			auth,
+			nil,
+			nil,
+			httpClient,
+		)
 	if err != nil {
 		fmt.Printf("[AzureAuth] Error creating adapter: %v\n", err)
 		return nil, err
 	}
+	if config.BaseURL != "" {
+		adapter.SetBaseUrl(strings.TrimSuffix(config.BaseURL, "/"))
+	}
 
-	client := msgraphsdk.NewGraphServiceClient(adapter)
-
-	return client, nil
+	return &GraphClient{
+		authenticatedClient: msgraphsdk.NewGraphServiceClient(adapter),
+		adapter:             adapter,
+		throttle:            throttle,
+		userPath:            userPath(userID),
+	}, nil
 }
@@ -0,0 +1,183 @@
+package mstodo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvDateTime_timeZone_isUTC(t *testing.T) {
+	dateTime := "2022-08-02T10:30:00.0000000"
+	timeZone := "Europe/Berlin"
+	dateTimeTimeZone := graphmodels.NewDateTimeTimeZone()
+	dateTimeTimeZone.SetDateTime(&dateTime)
+	dateTimeTimeZone.SetTimeZone(&timeZone)
+
+	converted, err := convDateTime(dateTimeTimeZone)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-08-02T08:30:00.0000000", converted)
+}
+
+func TestConvDateTime_notSet_isEmpty(t *testing.T) {
+	converted, err := convDateTime(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", converted)
+}
+
+func TestConvDateTime_unknownTimeZone_isError(t *testing.T) {
+	dateTime := "2022-08-02T10:30:00.0000000"
+	timeZone := "Mars Standard Time"
+	dateTimeTimeZone := graphmodels.NewDateTimeTimeZone()
+	dateTimeTimeZone.SetDateTime(&dateTime)
+	dateTimeTimeZone.SetTimeZone(&timeZone)
+
+	_, err := convDateTime(dateTimeTimeZone)
+
+	assert.Error(t, err)
+}
+
+func TestConvRecurrence_weeklyWithEndDate(t *testing.T) {
+	patternType := graphmodels.WEEKLY_RECURRENCEPATTERNTYPE
+	interval := int32(2)
+	pattern := graphmodels.NewRecurrencePattern()
+	pattern.SetType(&patternType)
+	pattern.SetInterval(&interval)
+	pattern.SetDaysOfWeek([]string{"tuesday"})
+	rangeType := graphmodels.ENDDATE_RECURRENCERANGETYPE
+	endDate := serialization.NewDateOnly(time.Date(2022, 9, 30, 0, 0, 0, 0, time.UTC))
+	recurrenceRange := graphmodels.NewRecurrenceRange()
+	recurrenceRange.SetType(&rangeType)
+	recurrenceRange.SetEndDate(endDate)
+	recurrenceData := graphmodels.NewPatternedRecurrence()
+	recurrenceData.SetPattern(pattern)
+	recurrenceData.SetRange(recurrenceRange)
+
+	recurrence := convRecurrence(recurrenceData)
+
+	assert.Equal(t, &models.Recurrence{
+		PatternType: models.TODO_RECURRENCE_WEEKLY,
+		Interval:    2,
+		DaysOfWeek:  []string{"tuesday"},
+		RangeType:   models.TODO_RECURRENCERANGE_ENDDATE,
+		EndDate:     "2022-09-30",
+	}, recurrence)
+}
+
+func TestConvRecurrence_notSet_isNil(t *testing.T) {
+	assert.Nil(t, convRecurrence(nil))
+}
+
+func TestConvTaskList_wellknownAndShared(t *testing.T) {
+	id := "AAMkAD"
+	displayName := "Tasks"
+	wellknownListName := graphmodels.DEFAULTLIST_WELLKNOWNLISTNAME
+	isOwner := true
+	isShared := true
+	listData := graphmodels.NewTodoTaskList()
+	listData.SetId(&id)
+	listData.SetDisplayName(&displayName)
+	listData.SetWellknownListName(&wellknownListName)
+	listData.SetIsOwner(&isOwner)
+	listData.SetIsShared(&isShared)
+
+	assert.Equal(t, models.TaskList{
+		ID:                id,
+		DisplayName:       displayName,
+		WellknownListName: models.TODO_WELLKNOWNLIST_DEFAULTLIST,
+		IsOwner:           true,
+		IsShared:          true,
+	}, convTaskList(listData))
+}
+
+func TestConvTaskList_notWellknown_isNone(t *testing.T) {
+	listData := graphmodels.NewTodoTaskList()
+
+	assert.Equal(t, models.TODO_WELLKNOWNLIST_NONE, convTaskList(listData).WellknownListName)
+}
+
+// newTestGraphClient returns a client that sends its requests to the given handler. It
+// does not wait before retrying a request.
+func newTestGraphClient(t *testing.T, handler http.Handler) *GraphClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := newGraphClient(&authentication.AnonymousAuthenticationProvider{}, "")
+	assert.NoError(t, err)
+	client.adapter.SetBaseUrl(server.URL)
+	client.throttle.sleep = func(time.Duration) {}
+
+	return client
+}
+
+func TestReadOpenTasks_preferUTC_unknownTimeZoneIsTaskErr(t *testing.T) {
+	var prefer string
+	client := newTestGraphClient(t, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			prefer = r.Header.Get("Prefer")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"value": [
+				{"id": "task-1", "title": "Milk", "status": "notStarted",
+					"dueDateTime": {"dateTime": "2022-08-02T00:00:00.0000000",
+						"timeZone": "W. Europe Standard Time"}},
+				{"id": "task-2", "title": "Bread", "status": "notStarted"}
+			]}`)
+		},
+	))
+	listID := "list-1"
+
+	tasks, taskErrs, _, err := client.ReadOpenTasks(&listID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `outlook.timezone="UTC"`, prefer)
+	if assert.Len(t, *tasks, 1) {
+		assert.Equal(t, "Bread", *(*tasks)[0].Title)
+	}
+	assert.ErrorContains(t, taskErrs["task-1"], "W. Europe Standard Time")
+}
+
+func TestReadOpenTasks_nextLink_allPagesFetched(t *testing.T) {
+	graphConfig := DefaultConfig()
+	graphConfig.PageSize = 2
+	Configure(graphConfig)
+	defer Configure(DefaultConfig())
+
+	var requestedTops []string
+	client := newTestGraphClient(t, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requestedTops = append(requestedTops, r.URL.Query().Get("$top"))
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("$skiptoken") == "" {
+				fmt.Fprintf(w, `{
+					"@odata.nextLink": "http://%s%s?$top=2&$skiptoken=page2",
+					"value": [
+						{"id": "task-1", "title": "Task 1", "status": "notStarted"},
+						{"id": "task-2", "title": "Task 2", "status": "notStarted"}
+					]}`, r.Host, r.URL.Path)
+				return
+			}
+			fmt.Fprint(w, `{"value": [
+				{"id": "task-3", "title": "Task 3", "status": "inProgress"}
+			]}`)
+		},
+	))
+	listID := "list-1"
+
+	tasks, taskErrs, pageCount, err := client.ReadOpenTasks(&listID)
+
+	assert.NoError(t, err)
+	assert.Empty(t, taskErrs)
+	assert.Equal(t, 2, pageCount)
+	assert.Len(t, *tasks, 3)
+	assert.Equal(t, "task-3", *(*tasks)[2].ToDoTaskID)
+	assert.Equal(t, []string{"2", "2"}, requestedTops)
+}
@@ -0,0 +1,279 @@
+package mstodo
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	khttp "github.com/microsoft/kiota-http-go"
+)
+
+// AuthFlow is the OAuth flow by which the access tokens for MS To-Do are acquired.
+type AuthFlow string
+
+const (
+	// The user signs in on another device by a device code. This is the default.
+	AuthFlowDeviceCode AuthFlow = "device_code"
+	// The user signs in by the default browser, which redirects to a local server.
+	AuthFlowInteractiveBrowser AuthFlow = "interactive_browser"
+	// The application authenticates itself by a client secret.
+	AuthFlowClientSecret AuthFlow = "client_secret"
+	// The application authenticates itself by a client certificate.
+	AuthFlowClientCertificate AuthFlow = "client_certificate"
+	// The credentials are read from the environment variables 'AZURE_TENANT_ID',
+	// 'AZURE_CLIENT_ID', 'AZURE_CLIENT_SECRET', 'AZURE_CLIENT_CERTIFICATE_PATH',
+	// 'AZURE_USERNAME' and 'AZURE_PASSWORD'.
+	AuthFlowEnvironment AuthFlow = "environment"
+)
+
+// DefaultAccount is the name of the account given by the top-level fields of the
+// credentials.yaml.
+const DefaultAccount = "default"
+
+// accountNamePattern matches the valid account names. The name is part of the name of
+// the token cache file.
+var accountNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// delegatedScopes are the scopes requested on behalf of a signed-in user.
+var delegatedScopes = []string{"Tasks.ReadWrite"}
+
+// applicationScopes are the scopes requested by the application itself. The permissions
+// are the application permissions granted to it on Azure.
+var applicationScopes = []string{"https://graph.microsoft.com/.default"}
+
+// Credentials are the settings of the credentials.yaml that are required to
+// authenticate against MS To-Do.
+type Credentials struct {
+	// Flow by which the access tokens are acquired. Defaults to the Device Code flow.
+	Flow AuthFlow `mapstructure:"flow"`
+	// Tenant ID of the application on Azure.
+	TenantID string `mapstructure:"tenant_id"`
+	// Client ID of the application on Azure.
+	ClientID string `mapstructure:"client_id"`
+	// Client secret of the application for the Client Secret flow.
+	ClientSecret string `mapstructure:"client_secret"`
+	// Path of a PEM or PKCS12 file with the certificate and the private key of the
+	// application for the Client Certificate flow.
+	CertificatePath string `mapstructure:"certificate_path"`
+	// Password of the certificate file, if it is encrypted.
+	CertificatePassword string `mapstructure:"certificate_password"`
+	// Redirect URL of the Interactive Browser flow, 'http://localhost:<port>'. If
+	// empty, a random port is used.
+	RedirectURL string `mapstructure:"redirect_url"`
+	// ID or user principal name of the user whose tasks are synced. Required by the
+	// flows without a signed-in user, as these cannot access '/me'.
+	UserID string `mapstructure:"user_id"`
+}
+
+// ValidateAccountName returns an error if the account name is empty or contains other
+// characters than letters, digits, '-' and '_'.
+func ValidateAccountName(account string) error {
+	if !accountNamePattern.MatchString(account) {
+		return fmt.Errorf(
+			"[AzureAuth] Invalid account name '%s', use letters, digits, '-' and '_'. "+
+				"Check the credentials.yaml file.",
+			account,
+		)
+	}
+	return nil
+}
+
+// Validate returns an error if a field required by the flow of the credentials is
+// missing.
+func (creds *Credentials) Validate() error {
+	required := map[string]string{}
+	switch creds.flow() {
+	case AuthFlowDeviceCode, AuthFlowInteractiveBrowser:
+		required["tenant_id"] = creds.TenantID
+		required["client_id"] = creds.ClientID
+	case AuthFlowClientSecret:
+		required["tenant_id"] = creds.TenantID
+		required["client_id"] = creds.ClientID
+		required["client_secret"] = creds.ClientSecret
+		required["user_id"] = creds.UserID
+	case AuthFlowClientCertificate:
+		required["tenant_id"] = creds.TenantID
+		required["client_id"] = creds.ClientID
+		required["certificate_path"] = creds.CertificatePath
+		required["user_id"] = creds.UserID
+	case AuthFlowEnvironment:
+		if !creds.isDelegated() {
+			required["user_id"] = creds.UserID
+		}
+	default:
+		return fmt.Errorf(
+			"[AzureAuth] Unknown auth flow '%s'. Check the credentials.yaml file.",
+			creds.Flow,
+		)
+	}
+
+	missing := []string{}
+	for _, field := range []string{
+		"tenant_id",
+		"client_id",
+		"client_secret",
+		"certificate_path",
+		"user_id",
+	} {
+		if value, isRequired := required[field]; isRequired && value == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf(
+			"[AzureAuth] Missing '%s' for auth flow '%s'. Check the credentials.yaml "+
+				"file.",
+			strings.Join(missing, "', '"),
+			creds.flow(),
+		)
+	}
+
+	if creds.flow() == AuthFlowInteractiveBrowser && creds.RedirectURL != "" {
+		redirectURL, err := url.Parse(creds.RedirectURL)
+		if err != nil ||
+			redirectURL.Scheme != "http" ||
+			redirectURL.Hostname() != "localhost" {
+			return fmt.Errorf(
+				"[AzureAuth] Invalid 'redirect_url' '%s', expected "+
+					"'http://localhost:<port>'. Check the credentials.yaml file.",
+				creds.RedirectURL,
+			)
+		}
+	}
+	return nil
+}
+
+// flow returns the flow of the credentials, which defaults to the Device Code flow.
+func (creds *Credentials) flow() AuthFlow {
+	if creds.Flow == "" {
+		return AuthFlowDeviceCode
+	}
+	return creds.Flow
+}
+
+// isDelegated returns 'true' if the tokens are acquired on behalf of a signed-in user
+// and 'false' if the application authenticates itself.
+func (creds *Credentials) isDelegated() bool {
+	switch creds.flow() {
+	case AuthFlowDeviceCode, AuthFlowInteractiveBrowser:
+		return true
+	case AuthFlowEnvironment:
+		return os.Getenv("AZURE_USERNAME") != ""
+	default:
+		return false
+	}
+}
+
+// newCredential creates the credential of the flow of the credentials and returns the
+// scopes to request. The tokens of the flows with a signed-in user are cached in the
+// given file.
+func newCredential(
+	creds *Credentials,
+	tokenCachePath string,
+) (azcore.TokenCredential, []string, error) {
+	var cred azcore.TokenCredential
+	var err error
+	switch creds.flow() {
+	case AuthFlowDeviceCode, AuthFlowInteractiveBrowser:
+		cred, err = newPublicClientCredential(creds, tokenCachePath)
+	case AuthFlowClientSecret:
+		cred, err = azidentity.NewClientSecretCredential(
+			creds.TenantID,
+			creds.ClientID,
+			creds.ClientSecret,
+			nil,
+		)
+	case AuthFlowClientCertificate:
+		cred, err = newClientCertificateCredential(creds)
+	case AuthFlowEnvironment:
+		cred, err = azidentity.NewEnvironmentCredential(nil)
+	default:
+		err = fmt.Errorf("[AzureAuth] Unknown auth flow '%s'.", creds.Flow)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if creds.isDelegated() {
+		return cred, delegatedScopes, nil
+	}
+	return cred, applicationScopes, nil
+}
+
+// newClientCertificateCredential reads the certificate file of the credentials and
+// creates a credential of the Client Certificate flow.
+func newClientCertificateCredential(creds *Credentials) (azcore.TokenCredential, error) {
+	content, err := os.ReadFile(creds.CertificatePath)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[AzureAuth] Failed to read certificate file '%s': %w",
+			creds.CertificatePath,
+			err,
+		)
+	}
+
+	var password []byte
+	if creds.CertificatePassword != "" {
+		password = []byte(creds.CertificatePassword)
+	}
+	certs, key, err := azidentity.ParseCertificates(content, password)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[AzureAuth] Failed to parse certificate file '%s': %w",
+			creds.CertificatePath,
+			err,
+		)
+	}
+
+	return azidentity.NewClientCertificateCredential(
+		creds.TenantID,
+		creds.ClientID,
+		certs,
+		key,
+		nil,
+	)
+}
+
+// userPathHandler is a middleware of the HTTP client that sends the requests for the
+// signed-in user, '/me', to the given user, '/users/<userID>'. It is required by the
+// flows without a signed-in user.
+type userPathHandler struct {
+	userID string
+}
+
+// Intercept replaces the path segment 'me' of the request by 'users/<userID>'.
+func (handler *userPathHandler) Intercept(
+	pipeline khttp.Pipeline,
+	middlewareIndex int,
+	req *http.Request,
+) (*http.Response, error) {
+	segments := strings.Split(req.URL.EscapedPath(), "/")
+	for i, segment := range segments {
+		if segment == "me" {
+			segments[i] = "users/" + url.PathEscape(handler.userID)
+			escapedPath := strings.Join(segments, "/")
+			path, err := url.PathUnescape(escapedPath)
+			if err != nil {
+				return nil, fmt.Errorf("[userPathHandler] Failed to rewrite path: %w", err)
+			}
+			req.URL.Path = path
+			req.URL.RawPath = escapedPath
+			break
+		}
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
+
+// userPath returns the path of the user whose tasks are accessed, relative to the base
+// URL of the Microsoft Graph API.
+func userPath(userID string) string {
+	if userID == "" {
+		return "/me"
+	}
+	return "/users/" + url.PathEscape(userID)
+}
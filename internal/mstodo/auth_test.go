@@ -0,0 +1,122 @@
+package mstodo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate_noFlow_isDeviceCode(t *testing.T) {
+	creds := Credentials{TenantID: "consumers", ClientID: "client"}
+
+	assert.NoError(t, creds.Validate())
+	assert.True(t, creds.isDelegated())
+}
+
+func TestValidate_deviceCodeWithoutClientID_isError(t *testing.T) {
+	creds := Credentials{Flow: AuthFlowDeviceCode, TenantID: "consumers"}
+
+	err := creds.Validate()
+
+	assert.ErrorContains(t, err, "'client_id'")
+}
+
+func TestValidate_clientSecretWithoutSecretAndUser_isError(t *testing.T) {
+	creds := Credentials{
+		Flow:     AuthFlowClientSecret,
+		TenantID: "tenant",
+		ClientID: "client",
+	}
+
+	err := creds.Validate()
+
+	assert.ErrorContains(t, err, "'client_secret', 'user_id'")
+}
+
+func TestValidate_clientCertificate_isValid(t *testing.T) {
+	creds := Credentials{
+		Flow:            AuthFlowClientCertificate,
+		TenantID:        "tenant",
+		ClientID:        "client",
+		CertificatePath: "/etc/twtodo/cert.pem",
+		UserID:          "jane@contoso.com",
+	}
+
+	assert.NoError(t, creds.Validate())
+	assert.False(t, creds.isDelegated())
+}
+
+func TestValidate_environmentWithUsername_requiresNoUser(t *testing.T) {
+	t.Setenv("AZURE_USERNAME", "jane@contoso.com")
+	creds := Credentials{Flow: AuthFlowEnvironment}
+
+	assert.NoError(t, creds.Validate())
+	assert.True(t, creds.isDelegated())
+}
+
+func TestValidate_environmentWithoutUsername_requiresUser(t *testing.T) {
+	t.Setenv("AZURE_USERNAME", "")
+	creds := Credentials{Flow: AuthFlowEnvironment}
+
+	err := creds.Validate()
+
+	assert.ErrorContains(t, err, "'user_id'")
+}
+
+func TestValidate_redirectURLNotLocalhost_isError(t *testing.T) {
+	creds := Credentials{
+		Flow:        AuthFlowInteractiveBrowser,
+		TenantID:    "consumers",
+		ClientID:    "client",
+		RedirectURL: "https://example.com:8400",
+	}
+
+	err := creds.Validate()
+
+	assert.ErrorContains(t, err, "'redirect_url'")
+}
+
+func TestValidate_unknownFlow_isError(t *testing.T) {
+	creds := Credentials{Flow: "password", TenantID: "consumers", ClientID: "client"}
+
+	err := creds.Validate()
+
+	assert.ErrorContains(t, err, "Unknown auth flow 'password'")
+}
+
+func TestNewGraphClient_userID_requestsSentToUser(t *testing.T) {
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requestedPath = r.URL.EscapedPath()
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"value": []}`))
+		},
+	))
+	t.Cleanup(server.Close)
+
+	client, err := newGraphClient(
+		&authentication.AnonymousAuthenticationProvider{},
+		"jane@contoso.com",
+	)
+	assert.NoError(t, err)
+	client.adapter.SetBaseUrl(server.URL)
+	client.throttle.sleep = func(time.Duration) {}
+
+	_, err = client.ReadLists()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/jane@contoso.com/todo/lists", requestedPath)
+	assert.Equal(t, "/users/jane@contoso.com", client.userPath)
+}
+
+func TestValidateAccountName(t *testing.T) {
+	assert.NoError(t, ValidateAccountName(DefaultAccount))
+	assert.NoError(t, ValidateAccountName("work_2-tenant"))
+	assert.Error(t, ValidateAccountName(""))
+	assert.Error(t, ValidateAccountName("../work"))
+}
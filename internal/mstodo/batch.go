@@ -0,0 +1,257 @@
+package mstodo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	jsonserialization "github.com/microsoft/kiota-serialization-json-go"
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+
+	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
+)
+
+// maxBatchSize is the maximum number of requests the Microsoft Graph API accepts in a
+// single JSON batch.
+const maxBatchSize = 20
+
+// batchRequest is a request in a JSON batch.
+type batchRequest struct {
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// batchResponse is the response to a request in a JSON batch.
+type batchResponse struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// batchErrorBody is the body of a failed response in a JSON batch.
+type batchErrorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ReadTasksByIDs retrieves the task data of the tasks with the given task IDs from a
+// list, given by a list ID. The tasks are read by JSON batches of up to 20 requests. The
+// tasks are returned by their task ID. A task that fails to be read, for example as it
+// does not exist, is returned in the errors by its task ID and does not affect the
+// other tasks. Requests throttled within a batch are sent again by another batch. An
+// error is only returned if a batch fails as a whole.
+func (graph GraphClient) ReadTasksByIDs(
+	listID *string,
+	taskIDs []string,
+) (map[string]*models.Task, map[string]error, error) {
+	tasks := map[string]*models.Task{}
+	taskErrs := map[string]error{}
+
+	for start := 0; start < len(taskIDs); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(taskIDs) {
+			end = len(taskIDs)
+		}
+
+		err := graph.readTasksBatch(listID, taskIDs[start:end], tasks, taskErrs)
+		if err != nil {
+			return tasks, taskErrs, err
+		}
+
+		for _, taskID := range taskIDs[start:end] {
+			_, isRead := tasks[taskID]
+			_, isFailed := taskErrs[taskID]
+			if !isRead && !isFailed {
+				taskErrs[taskID] = fmt.Errorf(
+					"[ReadTasksByIDs] No response for the task with ID '%s' in batch "+
+						"response.\n",
+					taskID,
+				)
+			}
+		}
+	}
+
+	fmt.Printf(
+		"[ReadTasksByIDs] %v tasks read, %v failed.\n",
+		len(tasks),
+		len(taskErrs),
+	)
+
+	return tasks, taskErrs, nil
+}
+
+// readTasksBatch reads the given tasks by a JSON batch into 'tasks' and 'taskErrs'. The
+// tasks whose request has been throttled are read again by another batch.
+func (graph GraphClient) readTasksBatch(
+	listID *string,
+	batchTaskIDs []string,
+	tasks map[string]*models.Task,
+	taskErrs map[string]error,
+) error {
+	for retry := 0; len(batchTaskIDs) > 0; retry++ {
+		responses, err := graph.sendTasksBatch(listID, batchTaskIDs)
+		if err != nil {
+			return err
+		}
+
+		throttledTaskIDs := []string{}
+		retryAfter := ""
+		for _, response := range responses {
+			index, err := strconv.Atoi(response.ID)
+			if err != nil || index < 0 || index >= len(batchTaskIDs) {
+				return fmt.Errorf(
+					"[ReadTasksByIDs] Unexpected request ID '%s' in batch response.\n",
+					response.ID,
+				)
+			}
+			taskID := batchTaskIDs[index]
+
+			if isThrottledStatus(response.Status) {
+				atomic.AddInt32(&graph.throttle.stat.ThrottledCount, 1)
+				if retry < config.MaxRetries {
+					throttledTaskIDs = append(throttledTaskIDs, taskID)
+					retryAfter = response.Headers["Retry-After"]
+					continue
+				}
+				atomic.AddInt32(&graph.throttle.stat.GaveUpCount, 1)
+			}
+
+			task, err := convBatchResponse(listID, &taskID, &response)
+			if err != nil {
+				taskErrs[taskID] = err
+				continue
+			}
+			tasks[taskID] = task
+		}
+
+		batchTaskIDs = throttledTaskIDs
+		if len(batchTaskIDs) > 0 {
+			delay := graph.throttle.retryDelay(retryAfter, retry)
+			fmt.Printf(
+				"[ReadTasksByIDs] %v requests of the batch throttled, retrying in %v.\n",
+				len(batchTaskIDs),
+				delay,
+			)
+			atomic.AddInt32(&graph.throttle.stat.RetryCount, int32(len(batchTaskIDs)))
+			graph.throttle.sleep(delay)
+		}
+	}
+	return nil
+}
+
+// sendTasksBatch sends a JSON batch that reads the given tasks. The ID of each request
+// is the index of its task ID.
+func (graph GraphClient) sendTasksBatch(
+	listID *string,
+	taskIDs []string,
+) ([]batchResponse, error) {
+	requests := []batchRequest{}
+	for _, taskID := range taskIDs {
+		requests = append(requests, batchRequest{
+			ID:     strconv.Itoa(len(requests)),
+			Method: "GET",
+			URL: fmt.Sprintf(
+				"%s/todo/lists/%s/tasks/%s?$expand=%s",
+				graph.userPath,
+				url.PathEscape(*listID),
+				url.PathEscape(taskID),
+				expandChecklistItems[0],
+			),
+			// The headers of the batch are not applied to its requests.
+			Headers: map[string]string{"Prefer": preferUTC},
+		})
+	}
+	content, err := json.Marshal(map[string][]batchRequest{"requests": requests})
+	if err != nil {
+		return nil, fmt.Errorf("[sendTasksBatch] Failed to marshall batch: %w\n", err)
+	}
+
+	requestInfo := abstractions.NewRequestInformation()
+	requestInfo.Method = abstractions.POST
+	requestInfo.UrlTemplate = "{+baseurl}/$batch"
+	requestInfo.Headers["Accept"] = "application/json"
+	requestInfo.Headers["Content-Type"] = "application/json"
+	requestInfo.Content = content
+
+	responseContent, err := graph.adapter.SendPrimitiveAsync(
+		requestInfo,
+		"[]byte",
+		nil,
+		abstractions.ErrorMappings{
+			"4XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+			"5XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[sendTasksBatch] Failed to read tasks of To-Do list '%s' by batch: %w\n",
+			*listID,
+			err,
+		)
+	}
+	responseBytes, _ := responseContent.([]byte)
+
+	var batch struct {
+		Responses []batchResponse `json:"responses"`
+	}
+	err = json.Unmarshal(responseBytes, &batch)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[sendTasksBatch] Failed to unmarshall batch response: %w\n",
+			err,
+		)
+	}
+
+	return batch.Responses, nil
+}
+
+// convBatchResponse converts the response to a request in a JSON batch into a task.
+func convBatchResponse(
+	listID *string,
+	taskID *string,
+	response *batchResponse,
+) (*models.Task, error) {
+	if response.Status >= 400 {
+		var errorBody batchErrorBody
+		_ = json.Unmarshal(response.Body, &errorBody)
+		return nil, fmt.Errorf(
+			"[ReadTasksByIDs] Failed to fetch the task with ID '%s' from To-Do list "+
+				"'%s': status %v %s: %s\n",
+			*taskID,
+			*listID,
+			response.Status,
+			errorBody.Error.Code,
+			errorBody.Error.Message,
+		)
+	}
+
+	parseNode, err := jsonserialization.NewJsonParseNode(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[ReadTasksByIDs] Failed to parse the task with ID '%s': %w\n",
+			*taskID,
+			err,
+		)
+	}
+	taskData, err := parseNode.GetObjectValue(
+		graphmodels.CreateTodoTaskFromDiscriminatorValue,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[ReadTasksByIDs] Failed to parse the task with ID '%s': %w\n",
+			*taskID,
+			err,
+		)
+	}
+
+	return convTask(listID, taskData.(graphmodels.TodoTaskable))
+}
@@ -0,0 +1,151 @@
+package mstodo
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeBatchRequests returns the requests of a JSON batch.
+func decodeBatchRequests(t *testing.T, r *http.Request) []batchRequest {
+	// The request body is compressed by the request adapter.
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		assert.NoError(t, err)
+		body = gzipReader
+	}
+	var batch struct {
+		Requests []batchRequest `json:"requests"`
+	}
+	err := json.NewDecoder(body).Decode(&batch)
+	assert.NoError(t, err)
+	return batch.Requests
+}
+
+// batchRequestTaskID returns the task ID of a request of a JSON batch that reads a task.
+func batchRequestTaskID(request *batchRequest) string {
+	path := strings.Split(request.URL, "?")[0]
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+func TestReadTasksByIDs_notFound_otherTasksAreRead(t *testing.T) {
+	var batchSizes []int
+	client := newTestGraphClient(t, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/$batch", r.URL.Path)
+			requests := decodeBatchRequests(t, r)
+			batchSizes = append(batchSizes, len(requests))
+
+			responses := []string{}
+			for _, request := range requests {
+				taskID := batchRequestTaskID(&request)
+				if taskID == "task-missing" {
+					responses = append(responses, fmt.Sprintf(`{
+						"id": "%s", "status": 404,
+						"body": {"error": {"code": "ErrorItemNotFound",
+							"message": "The object was not found."}}}`,
+						request.ID,
+					))
+					continue
+				}
+				responses = append(responses, fmt.Sprintf(`{
+					"id": "%s", "status": 200,
+					"body": {"id": "%s", "title": "Title %s", "status": "notStarted",
+						"checklistItems": []}}`,
+					request.ID,
+					taskID,
+					taskID,
+				))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"responses": [%s]}`, strings.Join(responses, ","))
+		},
+	))
+	listID := "list-1"
+	taskIDs := []string{"task-missing"}
+	for i := 0; i < 21; i++ {
+		taskIDs = append(taskIDs, fmt.Sprintf("task-%v", i))
+	}
+
+	tasks, taskErrs, err := client.ReadTasksByIDs(&listID, taskIDs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{20, 2}, batchSizes)
+	assert.Len(t, tasks, 21)
+	assert.Equal(t, "Title task-20", *tasks["task-20"].Title)
+	assert.Equal(t, "list-1", *tasks["task-20"].ToDoListID)
+	assert.NotNil(t, tasks["task-20"].ChecklistItems)
+	assert.Len(t, taskErrs, 1)
+	assert.ErrorContains(t, taskErrs["task-missing"], "404 ErrorItemNotFound")
+}
+
+func TestReadTasksByIDs_throttled_isReadAgain(t *testing.T) {
+	var batchedTaskIDs [][]string
+	client := newTestGraphClient(t, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests := decodeBatchRequests(t, r)
+			taskIDs := []string{}
+			responses := []string{}
+			for _, request := range requests {
+				taskID := batchRequestTaskID(&request)
+				taskIDs = append(taskIDs, taskID)
+				if taskID == "task-2" && len(batchedTaskIDs) == 0 {
+					responses = append(responses, fmt.Sprintf(`{
+						"id": "%s", "status": 429, "headers": {"Retry-After": "3"},
+						"body": {"error": {"code": "TooManyRequests"}}}`,
+						request.ID,
+					))
+					continue
+				}
+				responses = append(responses, fmt.Sprintf(`{
+					"id": "%s", "status": 200,
+					"body": {"id": "%s", "title": "Title", "status": "notStarted"}}`,
+					request.ID,
+					taskID,
+				))
+			}
+			batchedTaskIDs = append(batchedTaskIDs, taskIDs)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"responses": [%s]}`, strings.Join(responses, ","))
+		},
+	))
+	delays := recordDelays(client)
+	listID := "list-1"
+
+	tasks, taskErrs, err := client.ReadTasksByIDs(&listID, []string{"task-1", "task-2"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, taskErrs)
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, [][]string{{"task-1", "task-2"}, {"task-2"}}, batchedTaskIDs)
+	assert.Equal(t, []time.Duration{3 * time.Second}, *delays)
+	assert.Equal(t, int32(1), client.ReadThrottleStatistics().ThrottledCount)
+}
+
+func TestReadTasksByIDs_requestsPreferUTC(t *testing.T) {
+	var prefers []string
+	client := newTestGraphClient(t, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			for _, request := range decodeBatchRequests(t, r) {
+				prefers = append(prefers, request.Headers["Prefer"])
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"responses": [{"id": "0", "status": 200,
+				"body": {"id": "task-1", "title": "Milk", "status": "notStarted"}}]}`)
+		},
+	))
+	listID := "list-1"
+
+	_, _, err := client.ReadTasksByIDs(&listID, []string{"task-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`outlook.timezone="UTC"`}, prefers)
+}
@@ -0,0 +1,30 @@
+package mstodo
+
+// Config controls how the Microsoft Graph API is queried. It is read from the section
+// 'mstodo' of the config.yaml.
+type Config struct {
+	// Number of tasks fetched per page ('$top'). If 0, the page size of the Microsoft
+	// Graph API is used.
+	PageSize int32 `mapstructure:"page_size"`
+	// Number of times a request is retried after a transient error, for example if it
+	// has been throttled.
+	MaxRetries int `mapstructure:"max_retries"`
+	// Base URL of the Microsoft Graph API, for example of a fake To-Do server for
+	// testing. If empty, 'https://graph.microsoft.com/v1.0' is used.
+	BaseURL string `mapstructure:"base_url"`
+}
+
+var config = DefaultConfig()
+
+// DefaultConfig returns the config that is used if config.yaml has no 'mstodo' section.
+func DefaultConfig() Config {
+	return Config{
+		PageSize:   100,
+		MaxRetries: 5,
+	}
+}
+
+// Configure sets the config used by all subsequent Microsoft Graph API requests.
+func Configure(c Config) {
+	config = c
+}
@@ -0,0 +1,155 @@
+package mstodo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/me/todo/lists/item/tasks/delta"
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+
+	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
+)
+
+// ErrDeltaTokenExpired is returned by ReadTasksDelta if the delta link is no longer
+// accepted by MS To-Do. The changes have to be queried again without delta link.
+var ErrDeltaTokenExpired = errors.New("the delta token has expired")
+
+// deltaTokenExpiredCodes are the error codes the Microsoft Graph API returns for a delta
+// token that has expired or is otherwise invalid.
+var deltaTokenExpiredCodes = []string{
+	"syncStateNotFound",
+	"syncStateInvalid",
+	"resyncRequired",
+}
+
+// TasksDelta are the changes of the tasks of a To-Do list since a delta query.
+type TasksDelta struct {
+	// Tasks created or changed since the delta link was issued. If no delta link was
+	// given, these are all tasks of the list.
+	Tasks []models.Task
+	// IDs of the tasks deleted since the delta link was issued.
+	RemovedTaskIDs []string
+	// Errors of the tasks that have changed but failed to be converted, for example due
+	// to an unknown time zone. Key is the task ID.
+	TaskErrs map[string]error
+	// Delta link to query the changes since this query.
+	DeltaLink string
+}
+
+// ReadTasksDelta uses the delta query of the Microsoft Graph API to fetch the tasks of a
+// To-Do list that have changed or have been deleted since the given delta link was
+// issued. If no delta link is given, all tasks of the list are fetched.
+// ErrDeltaTokenExpired is returned if the delta link has expired.
+func (graph GraphClient) ReadTasksDelta(
+	listID *string,
+	deltaLink *string,
+) (*TasksDelta, error) {
+	requestBuilder := graph.authenticatedClient.Me().
+		Todo().
+		ListsById(*listID).
+		Tasks().
+		Delta()
+	if deltaLink != nil && *deltaLink != "" {
+		requestBuilder = delta.NewDeltaRequestBuilder(*deltaLink, graph.adapter)
+	}
+
+	tasksDelta := &TasksDelta{
+		Tasks:          []models.Task{},
+		RemovedTaskIDs: []string{},
+		TaskErrs:       map[string]error{},
+	}
+	for {
+		deltaResponse, err := requestBuilder.Get()
+		if err != nil {
+			if isDeltaTokenExpired(err) {
+				return nil, fmt.Errorf(
+					"[ReadTasksDelta] Failed to fetch the changed tasks of To-Do list "+
+						"'%s': %w",
+					*listID,
+					ErrDeltaTokenExpired,
+				)
+			}
+			return nil, fmt.Errorf(
+				"[ReadTasksDelta] Failed to fetch the changed tasks of To-Do list "+
+					"'%s': %w\n",
+				*listID,
+				err,
+			)
+		}
+
+		tasksDelta.add(listID, deltaResponse.GetValue())
+
+		// The changes are paged. The delta link is only returned with the last page.
+		nextLink := additionalDataString(deltaResponse.GetAdditionalData(), "@odata.nextLink")
+		if nextLink != "" {
+			requestBuilder = delta.NewDeltaRequestBuilder(nextLink, graph.adapter)
+			continue
+		}
+		tasksDelta.DeltaLink = additionalDataString(
+			deltaResponse.GetAdditionalData(),
+			"@odata.deltaLink",
+		)
+		break
+	}
+
+	fmt.Printf(
+		"[ReadTasksDelta] %v changed and %v removed tasks fetched, %v failed.\n",
+		len(tasksDelta.Tasks),
+		len(tasksDelta.RemovedTaskIDs),
+		len(tasksDelta.TaskErrs),
+	)
+
+	return tasksDelta, nil
+}
+
+// add adds the task data of a page of a delta query to the changes. A task that fails
+// to be converted is added to the errors and does not affect the other tasks.
+func (tasksDelta *TasksDelta) add(listID *string, tasksData []graphmodels.TodoTaskable) {
+	for _, taskData := range tasksData {
+		if _, isRemoved := taskData.GetAdditionalData()["@removed"]; isRemoved {
+			tasksDelta.RemovedTaskIDs = append(tasksDelta.RemovedTaskIDs, *taskData.GetId())
+			continue
+		}
+
+		task, err := convTask(listID, taskData)
+		if err != nil {
+			if tasksDelta.TaskErrs == nil {
+				tasksDelta.TaskErrs = map[string]error{}
+			}
+			tasksDelta.TaskErrs[*taskData.GetId()] = err
+			continue
+		}
+		tasksDelta.Tasks = append(tasksDelta.Tasks, *task)
+	}
+}
+
+// additionalDataString returns the string value of an annotation that is not part of
+// the data model, for example '@odata.nextLink'. An empty string is returned if the
+// annotation is missing.
+func additionalDataString(additionalData map[string]interface{}, key string) string {
+	value, ok := additionalData[key].(*string)
+	if !ok || value == nil {
+		return ""
+	}
+	return *value
+}
+
+// isDeltaTokenExpired returns 'true' if the error received from the Microsoft Graph API
+// tells that the delta token has expired.
+func isDeltaTokenExpired(err error) bool {
+	var odataErr *odataerrors.ODataError
+	if !errors.As(err, &odataErr) ||
+		odataErr.GetError() == nil ||
+		odataErr.GetError().GetCode() == nil {
+		return false
+	}
+
+	for _, code := range deltaTokenExpiredCodes {
+		if strings.EqualFold(*odataErr.GetError().GetCode(), code) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package mstodo
+
+import (
+	"errors"
+	"testing"
+
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newODataError(code string) error {
+	mainError := odataerrors.NewMainError()
+	mainError.SetCode(&code)
+	odataErr := odataerrors.NewODataError()
+	odataErr.SetError(mainError)
+	return odataErr
+}
+
+func TestIsDeltaTokenExpired(t *testing.T) {
+	assert.True(t, isDeltaTokenExpired(newODataError("syncStateNotFound")))
+	assert.True(t, isDeltaTokenExpired(newODataError("resyncRequired")))
+	assert.False(t, isDeltaTokenExpired(newODataError("ErrorItemNotFound")))
+	assert.False(t, isDeltaTokenExpired(errors.New("syncStateNotFound")))
+}
+
+func TestTasksDeltaAdd_removedTask_isRemovedTaskID(t *testing.T) {
+	listID := "list-1"
+	changedID := "task-1"
+	changedTitle := "Changed"
+	status := graphmodels.NOTSTARTED_TASKSTATUS
+	changedData := graphmodels.NewTodoTask()
+	changedData.SetId(&changedID)
+	changedData.SetTitle(&changedTitle)
+	changedData.SetStatus(&status)
+	removedID := "task-2"
+	removedData := graphmodels.NewTodoTask()
+	removedData.SetId(&removedID)
+	removedData.SetAdditionalData(map[string]interface{}{
+		"@removed": map[string]interface{}{"reason": "deleted"},
+	})
+	tasksDelta := &TasksDelta{}
+
+	tasksDelta.add(&listID, []graphmodels.TodoTaskable{changedData, removedData})
+
+	assert.Empty(t, tasksDelta.TaskErrs)
+	assert.Equal(t, []string{"task-2"}, tasksDelta.RemovedTaskIDs)
+	assert.Len(t, tasksDelta.Tasks, 1)
+	assert.Equal(t, "Changed", *tasksDelta.Tasks[0].Title)
+	assert.Nil(t, tasksDelta.Tasks[0].ChecklistItems, "Checklist items are not expanded.")
+}
+
+func TestTasksDeltaAdd_unknownTimeZone_isTaskErr(t *testing.T) {
+	listID := "list-1"
+	status := graphmodels.NOTSTARTED_TASKSTATUS
+	newTaskData := func(id string, title string) graphmodels.TodoTaskable {
+		taskData := graphmodels.NewTodoTask()
+		taskData.SetId(&id)
+		taskData.SetTitle(&title)
+		taskData.SetStatus(&status)
+		return taskData
+	}
+	failedData := newTaskData("task-1", "Milk")
+	dueDateTime := "2022-08-02T00:00:00.0000000"
+	dueDateTimeTimeZone := graphmodels.NewDateTimeTimeZone()
+	dueDateTimeTimeZone.SetDateTime(&dueDateTime)
+	timeZone := "Unknown Standard Time"
+	dueDateTimeTimeZone.SetTimeZone(&timeZone)
+	failedData.SetDueDateTime(dueDateTimeTimeZone)
+	tasksDelta := &TasksDelta{}
+
+	tasksDelta.add(
+		&listID,
+		[]graphmodels.TodoTaskable{failedData, newTaskData("task-2", "Bread")},
+	)
+
+	assert.ErrorContains(t, tasksDelta.TaskErrs["task-1"], "Unknown Standard Time")
+	if assert.Len(t, tasksDelta.Tasks, 1) {
+		assert.Equal(t, "Bread", *tasksDelta.Tasks[0].Title)
+	}
+}
+
+func TestAdditionalDataString_nextLink(t *testing.T) {
+	nextLink := "https://graph.microsoft.com/v1.0/next"
+	additionalData := map[string]interface{}{"@odata.nextLink": &nextLink}
+
+	assert.Equal(t, nextLink, additionalDataString(additionalData, "@odata.nextLink"))
+	assert.Equal(t, "", additionalDataString(additionalData, "@odata.deltaLink"))
+}
@@ -0,0 +1,227 @@
+package mstodo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microsoft/kiota-abstractions-go/authentication"
+	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/simachri/taskwarrior-ms-todo/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeServerClient starts a fake To-Do server and returns a client that is configured
+// to query it.
+func newFakeServerClient(t *testing.T) (*test.FakeToDoServer, *GraphClient) {
+	fake := test.NewFakeToDoServer(t)
+	graphConfig := DefaultConfig()
+	graphConfig.BaseURL = fake.URL
+	Configure(graphConfig)
+	t.Cleanup(func() { Configure(DefaultConfig()) })
+
+	client, err := NewGraphClient(&authentication.AnonymousAuthenticationProvider{})
+	assert.NoError(t, err)
+	client.throttle.sleep = func(time.Duration) {}
+
+	return fake, client
+}
+
+func TestFakeServer_readAndCreateLists(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	groceriesID := fake.AddList("Groceries")
+	displayName := "Work"
+
+	createdList, err := client.CreateList(&displayName)
+	assert.NoError(t, err)
+	lists, err := client.ReadLists()
+
+	assert.NoError(t, err)
+	assert.Len(t, *lists, 2)
+	assert.Equal(t, groceriesID, (*lists)[0].ID)
+	assert.Equal(t, "Groceries", (*lists)[0].DisplayName)
+	assert.Equal(t, createdList.ID, (*lists)[1].ID)
+}
+
+func TestFakeServer_readLists_allPagesFetched(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	fake.ListPageSize = 2
+	for _, displayName := range []string{"Tasks", "Groceries", "Work", "Home", "Errands"} {
+		fake.AddList(displayName)
+	}
+
+	lists, err := client.ReadLists()
+
+	if assert.NoError(t, err) && assert.Len(t, *lists, 5) {
+		assert.Equal(t, "Tasks", (*lists)[0].DisplayName)
+		assert.Equal(t, "Errands", (*lists)[4].DisplayName)
+	}
+}
+
+func TestFakeServer_readOpenTasks_completedSkippedAndPaged(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	config.PageSize = 2
+	listID := fake.AddList("Groceries")
+	fake.AddTask(listID, test.FakeTask{"title": "Milk"})
+	fake.AddTask(listID, test.FakeTask{"title": "Bread", "status": "completed"})
+	fake.AddTask(listID, test.FakeTask{"title": "Eggs", "status": "inProgress"})
+	fake.AddTask(listID, test.FakeTask{
+		"title": "Cheese",
+		"checklistItems": []interface{}{
+			map[string]interface{}{"id": "item-1", "displayName": "Gouda"},
+		},
+	})
+
+	tasks, taskErrs, pageCount, err := client.ReadOpenTasks(&listID)
+
+	assert.NoError(t, err)
+	assert.Empty(t, taskErrs)
+	assert.Equal(t, 2, pageCount)
+	titles := []string{}
+	for _, task := range *tasks {
+		titles = append(titles, *task.Title)
+	}
+	assert.Equal(t, []string{"Milk", "Eggs", "Cheese"}, titles)
+	assert.Equal(t, "Gouda", *(*tasks)[2].ChecklistItems[0].Title)
+}
+
+func TestFakeServer_readTasksDelta_changesSinceDeltaLink(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	listID := fake.AddList("Groceries")
+	milkID := fake.AddTask(listID, test.FakeTask{"title": "Milk"})
+	breadID := fake.AddTask(listID, test.FakeTask{"title": "Bread"})
+	fake.AddTask(listID, test.FakeTask{"title": "Eggs"})
+
+	tasksDelta, err := client.ReadTasksDelta(&listID, nil)
+	assert.NoError(t, err)
+	assert.Len(t, tasksDelta.Tasks, 3)
+	assert.Empty(t, tasksDelta.RemovedTaskIDs)
+
+	fake.UpdateTask(listID, milkID, test.FakeTask{"title": "Oat milk"})
+	fake.DeleteTask(listID, breadID)
+	tasksDelta, err = client.ReadTasksDelta(&listID, &tasksDelta.DeltaLink)
+
+	assert.NoError(t, err)
+	assert.Len(t, tasksDelta.Tasks, 1)
+	assert.Equal(t, "Oat milk", *tasksDelta.Tasks[0].Title)
+	assert.Equal(t, []string{breadID}, tasksDelta.RemovedTaskIDs)
+}
+
+func TestFakeServer_readTasksDelta_expiredDeltaLink_isErrDeltaTokenExpired(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	listID := fake.AddList("Groceries")
+	tasksDelta, err := client.ReadTasksDelta(&listID, nil)
+	assert.NoError(t, err)
+
+	fake.ExpireDeltaTokens()
+	_, err = client.ReadTasksDelta(&listID, &tasksDelta.DeltaLink)
+
+	assert.ErrorIs(t, err, ErrDeltaTokenExpired)
+}
+
+func TestFakeServer_readTasksByIDs_missingTaskIsError(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	listID := fake.AddList("Groceries")
+	milkID := fake.AddTask(listID, test.FakeTask{"title": "Milk"})
+
+	tasks, taskErrs, err := client.ReadTasksByIDs(&listID, []string{milkID, "task-404"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Milk", *tasks[milkID].Title)
+	assert.NotNil(t, tasks[milkID].ChecklistItems, "Checklist items are not expanded.")
+	assert.ErrorContains(t, taskErrs["task-404"], "ErrorItemNotFound")
+}
+
+func TestFakeServer_createAndUpdateTask(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	listID := fake.AddList("Groceries")
+	title := "Milk"
+	importance := models.TODO_IMPORTANCE_HIGH
+
+	createdTask, err := client.CreateTask(&listID, &models.Task{
+		Title:      &title,
+		Importance: &importance,
+		Status:     models.TW_TASKSTATUS_PENDING,
+	})
+	assert.NoError(t, err)
+
+	updatedTitle := "Oat milk"
+	createdTask.Title = &updatedTitle
+	createdTask.Status = models.TW_TASKSTATUS_COMPLETED
+	completedAt := "2022-08-02T10:30:00.0000000"
+	createdTask.CompletedAt = &completedAt
+	err = client.UpdateTask(createdTask)
+	assert.NoError(t, err)
+
+	task, ok := fake.Task(listID, *createdTask.ToDoTaskID)
+	assert.True(t, ok)
+	assert.Equal(t, "Oat milk", task["title"])
+	assert.Equal(t, "completed", task["status"])
+	assert.Equal(t, "high", task["importance"])
+}
+
+func TestFakeServer_updateTask_dueDateRemoved_isCleared(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	listID := fake.AddList("Groceries")
+	taskID := fake.AddTask(listID, test.FakeTask{
+		"title": "Milk",
+		"dueDateTime": map[string]interface{}{
+			"dateTime": "2022-08-02T00:00:00.0000000",
+			"timeZone": "UTC",
+		},
+	})
+	task, err := client.ReadTaskByID(&listID, &taskID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-08-02T00:00:00.0000000", *task.DueAt)
+
+	noDueAt := ""
+	task.DueAt = &noDueAt
+	err = client.UpdateTask(task)
+	assert.NoError(t, err)
+
+	fakeTask, _ := fake.Task(listID, taskID)
+	assert.NotContains(t, fakeTask, "dueDateTime")
+	task, err = client.ReadTaskByID(&listID, &taskID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", *task.DueAt)
+	assert.Equal(t, "Milk", *task.Title)
+}
+
+func TestFakeServer_updateTask_deferredTask_staysDeferred(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	listID := fake.AddList("Groceries")
+	taskID := fake.AddTask(listID, test.FakeTask{"title": "Milk", "status": "deferred"})
+	task, err := client.ReadTaskByID(&listID, &taskID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TW_TASKSTATUS_WAITING, task.Status)
+
+	oatMilk := "Oat milk"
+	task.Title = &oatMilk
+	err = client.UpdateTask(task)
+	assert.NoError(t, err)
+
+	fakeTask, _ := fake.Task(listID, taskID)
+	assert.Equal(t, "deferred", fakeTask["status"])
+	assert.Equal(t, "Oat milk", fakeTask["title"])
+}
+
+func TestFakeServer_updateTask_noteNil_keepsHTMLBody(t *testing.T) {
+	fake, client := newFakeServerClient(t)
+	listID := fake.AddList("Groceries")
+	body := map[string]interface{}{
+		"content":     `<p>See <a href="https://example.com">recipe</a></p>`,
+		"contentType": "html",
+	}
+	taskID := fake.AddTask(listID, test.FakeTask{"title": "Milk", "body": body})
+	task, err := client.ReadTaskByID(&listID, &taskID)
+	assert.NoError(t, err)
+
+	oatMilk := "Oat milk"
+	task.Title = &oatMilk
+	task.Note = nil
+	err = client.UpdateTask(task)
+	assert.NoError(t, err)
+
+	fakeTask, _ := fake.Task(listID, taskID)
+	assert.Equal(t, body, fakeTask["body"])
+	assert.Equal(t, "Oat milk", fakeTask["title"])
+}
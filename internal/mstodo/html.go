@@ -0,0 +1,51 @@
+package mstodo
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlToText converts the HTML content of a task body into plain text. Block elements
+// and line breaks are converted into newlines.
+func htmlToText(content string) string {
+	var text strings.Builder
+	skip := false
+
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			// io.EOF or invalid HTML: return what has been parsed so far.
+			return normalizeText(text.String())
+
+		case html.TextToken:
+			if !skip {
+				text.Write(tokenizer.Text())
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			tagName, _ := tokenizer.TagName()
+			switch string(tagName) {
+			case "script", "style", "head", "title":
+				// The content of these elements is not visible.
+				skip = tokenType == html.StartTagToken
+			case "br", "p", "div", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6":
+				text.WriteString("\n")
+			}
+		}
+	}
+}
+
+// normalizeText trims the whitespace of each line and removes empty lines.
+func normalizeText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
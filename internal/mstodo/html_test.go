@@ -0,0 +1,23 @@
+package mstodo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLToText_blocksAndEntities(t *testing.T) {
+	content := "<html><head><style>p { color: red; }</style></head><body>" +
+		"<p>Call Bob&#39;s   dentist</p><div>Ask for<br>an appointment &amp; price</div>" +
+		"</body></html>"
+
+	text := htmlToText(content)
+
+	assert.Equal(t, "Call Bob's dentist\nAsk for\nan appointment & price", text)
+}
+
+func TestNormalizeText_emptyLines_areRemoved(t *testing.T) {
+	text := normalizeText("  foo \r\n\r\n bar  baz\n")
+
+	assert.Equal(t, "foo\nbar baz", text)
+}
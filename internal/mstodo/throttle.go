@@ -0,0 +1,177 @@
+package mstodo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	khttp "github.com/microsoft/kiota-http-go"
+)
+
+// ThrottleStatistics counts the requests to the Microsoft Graph API that failed with a
+// transient error.
+type ThrottleStatistics struct {
+	// Responses with status 429 'Too Many Requests' or 503 'Service Unavailable'.
+	ThrottledCount int32
+	// Requests that have been sent again after a transient error.
+	RetryCount int32
+	// Requests that failed as the transient error persisted after all retries.
+	GaveUpCount int32
+}
+
+// throttleHandler is a middleware of the HTTP client that retries a request after a
+// transient error. It waits for the 'Retry-After' header of the response and otherwise
+// backs off exponentially with jitter. It replaces the retry handler of the Microsoft
+// Graph SDK, which does not resend the request body.
+type throttleHandler struct {
+	// Delay before the first retry if the response has no 'Retry-After' header. It
+	// doubles with each retry.
+	baseDelay time.Duration
+	// Maximum delay between two retries if the response has no 'Retry-After' header.
+	maxDelay time.Duration
+	// sleep waits for the given duration. It is replaced in tests.
+	sleep func(time.Duration)
+	stat  ThrottleStatistics
+}
+
+func newThrottleHandler() *throttleHandler {
+	return &throttleHandler{
+		baseDelay: time.Second,
+		maxDelay:  time.Minute,
+		sleep:     time.Sleep,
+	}
+}
+
+// Intercept sends the request and retries it up to the configured number of retries as
+// long as it fails with a transient error.
+func (handler *throttleHandler) Intercept(
+	pipeline khttp.Pipeline,
+	middlewareIndex int,
+	req *http.Request,
+) (*http.Response, error) {
+	// The body is read by sending the request, keep it to send it again.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("[throttleHandler] Failed to read request body: %w", err)
+		}
+	}
+
+	for retry := 0; ; retry++ {
+		attemptReq := req.Clone(req.Context())
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+			attemptReq.ContentLength = int64(len(body))
+		}
+		if retry > 0 {
+			attemptReq.Header.Set("Retry-Attempt", strconv.Itoa(retry))
+		}
+
+		resp, err := pipeline.Next(attemptReq, middlewareIndex)
+		if !isTransient(req, resp, err) {
+			return resp, err
+		}
+
+		retryAfter := ""
+		if resp != nil {
+			retryAfter = resp.Header.Get("Retry-After")
+			if isThrottledStatus(resp.StatusCode) {
+				atomic.AddInt32(&handler.stat.ThrottledCount, 1)
+			}
+		}
+		if retry >= config.MaxRetries {
+			atomic.AddInt32(&handler.stat.GaveUpCount, 1)
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		delay := handler.retryDelay(retryAfter, retry)
+		fmt.Printf(
+			"[throttleHandler] %s %s failed with a transient error, retrying in %v.\n",
+			req.Method,
+			req.URL.Path,
+			delay,
+		)
+		atomic.AddInt32(&handler.stat.RetryCount, 1)
+		handler.sleep(delay)
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryDelay returns the delay before the given retry, counted from 0. The delay given
+// by the 'Retry-After' header of the response is honored. Otherwise, the delay doubles
+// with each retry and a random jitter of up to half the delay is subtracted such that
+// concurrent requests do not retry at the same time.
+func (handler *throttleHandler) retryDelay(retryAfter string, retry int) time.Duration {
+	if delay, ok := parseRetryAfter(retryAfter, time.Now()); ok {
+		return delay
+	}
+
+	delay := handler.maxDelay
+	if retry < 30 && handler.baseDelay<<retry < handler.maxDelay {
+		delay = handler.baseDelay << retry
+	}
+	if delay < 2 {
+		return delay
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// statistics returns the counts of the requests that failed with a transient error.
+func (handler *throttleHandler) statistics() ThrottleStatistics {
+	return ThrottleStatistics{
+		ThrottledCount: atomic.LoadInt32(&handler.stat.ThrottledCount),
+		RetryCount:     atomic.LoadInt32(&handler.stat.RetryCount),
+		GaveUpCount:    atomic.LoadInt32(&handler.stat.GaveUpCount),
+	}
+}
+
+// parseRetryAfter parses the value of a 'Retry-After' header, which is either a number
+// of seconds or a HTTP date. 'false' is returned if the value is missing or invalid.
+func parseRetryAfter(retryAfter string, now time.Time) (time.Duration, bool) {
+	if retryAfter == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		if date.Before(now) {
+			return 0, true
+		}
+		return date.Sub(now), true
+	}
+	return 0, false
+}
+
+// isThrottledStatus returns 'true' if the status code tells that the Microsoft Graph API
+// has throttled the request.
+func isThrottledStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusServiceUnavailable
+}
+
+// isTransient returns 'true' if the request failed with an error that may not occur
+// again when the request is retried.
+func isTransient(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// The request has not been canceled but has failed, for example due to a
+		// network error. A 'POST' is not sent again as it may have been processed.
+		return req.Context().Err() == nil && req.Method != http.MethodPost
+	}
+	return isThrottledStatus(resp.StatusCode) ||
+		resp.StatusCode == http.StatusGatewayTimeout
+}
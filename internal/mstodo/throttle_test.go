@@ -0,0 +1,127 @@
+package mstodo
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordDelays makes the client record the delays before retries instead of waiting.
+func recordDelays(client *GraphClient) *[]time.Duration {
+	delays := []time.Duration{}
+	client.throttle.sleep = func(delay time.Duration) {
+		delays = append(delays, delay)
+	}
+	return &delays
+}
+
+func TestThrottleHandler_retryAfter_isHonored(t *testing.T) {
+	requestCount := 0
+	client := newTestGraphClient(t, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			if requestCount == 1 {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"value": [{"id": "list-1", "displayName": "Tasks"}]}`)
+		},
+	))
+	delays := recordDelays(client)
+
+	lists, err := client.ReadLists()
+
+	assert.NoError(t, err)
+	assert.Len(t, *lists, 1)
+	assert.Equal(t, []time.Duration{7 * time.Second}, *delays)
+	assert.Equal(t, ThrottleStatistics{
+		ThrottledCount: 1,
+		RetryCount:     1,
+		GaveUpCount:    0,
+	}, client.ReadThrottleStatistics())
+}
+
+func TestThrottleHandler_noRetryAfter_backsOffUntilMaxRetries(t *testing.T) {
+	toDoConfig := DefaultConfig()
+	toDoConfig.MaxRetries = 3
+	Configure(toDoConfig)
+	defer Configure(DefaultConfig())
+
+	requestCount := 0
+	client := newTestGraphClient(t, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		},
+	))
+	delays := recordDelays(client)
+
+	_, err := client.ReadLists()
+
+	assert.Error(t, err)
+	assert.Equal(t, 4, requestCount)
+	assert.Len(t, *delays, 3)
+	for retry, delay := range *delays {
+		maxDelay := time.Second << retry
+		assert.LessOrEqual(t, delay, maxDelay, "Retry %v", retry)
+		assert.Greater(t, delay, maxDelay/2, "Retry %v", retry)
+	}
+	assert.Equal(t, ThrottleStatistics{
+		ThrottledCount: 4,
+		RetryCount:     3,
+		GaveUpCount:    1,
+	}, client.ReadThrottleStatistics())
+}
+
+func TestThrottleHandler_patch_bodyIsSentAgain(t *testing.T) {
+	var bodyLengths []int64
+	client := newTestGraphClient(t, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			bodyLengths = append(bodyLengths, r.ContentLength)
+			if len(bodyLengths) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	recordDelays(client)
+	listID := "list-1"
+	taskID := "task-1"
+	title := "Title"
+
+	err := client.UpdateTask(&models.Task{
+		ToDoListID: &listID,
+		ToDoTaskID: &taskID,
+		Title:      &title,
+		Status:     models.TW_TASKSTATUS_PENDING,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, bodyLengths, 2)
+	assert.Greater(t, bodyLengths[1], int64(0))
+	assert.Equal(t, bodyLengths[0], bodyLengths[1])
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 8, 2, 10, 30, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter("Tue, 02 Aug 2022 10:30:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,206 @@
+package mstodo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/cache"
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
+)
+
+// tokenCache persists the token cache of MSAL, which holds the access and refresh
+// tokens, in a file that is only accessible by the user.
+type tokenCache struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// Replace reads the token cache from its file. The cache is left empty if the file does
+// not exist.
+func (tokenCache *tokenCache) Replace(cache cache.Unmarshaler, key string) {
+	tokenCache.mutex.Lock()
+	defer tokenCache.mutex.Unlock()
+
+	content, err := os.ReadFile(tokenCache.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Printf(
+				"[tokenCache] Failed to read token cache file '%s': %v\n",
+				tokenCache.path,
+				err,
+			)
+		}
+		return
+	}
+
+	err = cache.Unmarshal(content)
+	if err != nil {
+		fmt.Printf(
+			"[tokenCache] Failed to unmarshall token cache file '%s': %v\n",
+			tokenCache.path,
+			err,
+		)
+	}
+}
+
+// Export writes the token cache to its file with permissions 0600.
+func (tokenCache *tokenCache) Export(cache cache.Marshaler, key string) {
+	tokenCache.mutex.Lock()
+	defer tokenCache.mutex.Unlock()
+
+	content, err := cache.Marshal()
+	if err != nil {
+		fmt.Printf("[tokenCache] Failed to marshall token cache: %v\n", err)
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(tokenCache.path), 0700)
+	if err != nil {
+		fmt.Printf(
+			"[tokenCache] Failed to create directory of '%s': %v\n",
+			tokenCache.path,
+			err,
+		)
+		return
+	}
+
+	// Write to a temporary file first such that the cache is not corrupted if the
+	// server is stopped while writing.
+	tmpPath := tokenCache.path + ".tmp"
+	err = os.WriteFile(tmpPath, content, 0600)
+	if err == nil {
+		err = os.Rename(tmpPath, tokenCache.path)
+	}
+	if err != nil {
+		fmt.Printf(
+			"[tokenCache] Failed to write token cache file '%s': %v\n",
+			tokenCache.path,
+			err,
+		)
+	}
+}
+
+// publicClientCredential acquires tokens on behalf of a signed-in user by the Device
+// Code or the Interactive Browser flow. The tokens are cached in a file such that they
+// are refreshed silently after a restart. The user is only asked to sign in if no token
+// is cached or it cannot be refreshed.
+type publicClientCredential struct {
+	client public.Client
+	// Sign in by the browser instead of a device code.
+	isInteractive bool
+	// Redirect URL of the Interactive Browser flow.
+	redirectURL string
+	// Serializes the token requests such that the user is asked only once to sign in.
+	mutex sync.Mutex
+}
+
+func newPublicClientCredential(
+	creds *Credentials,
+	tokenCachePath string,
+) (*publicClientCredential, error) {
+	client, err := public.New(
+		creds.ClientID,
+		public.WithAuthority("https://login.microsoftonline.com/"+creds.TenantID),
+		public.WithCache(&tokenCache{path: tokenCachePath}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[AzureAuth] Failed to create MSAL client: %w", err)
+	}
+	return &publicClientCredential{
+		client:        client,
+		isInteractive: creds.flow() == AuthFlowInteractiveBrowser,
+		redirectURL:   creds.RedirectURL,
+	}, nil
+}
+
+// GetToken returns an access token for the requested scopes. A cached token is used or
+// refreshed. If that fails, the user is asked to sign in.
+func (cred *publicClientCredential) GetToken(
+	ctx context.Context,
+	options policy.TokenRequestOptions,
+) (azcore.AccessToken, error) {
+	cred.mutex.Lock()
+	defer cred.mutex.Unlock()
+
+	for _, account := range cred.client.Accounts() {
+		result, err := cred.client.AcquireTokenSilent(
+			ctx,
+			options.Scopes,
+			public.WithSilentAccount(account),
+		)
+		if err == nil {
+			return azcore.AccessToken{
+				Token:     result.AccessToken,
+				ExpiresOn: result.ExpiresOn,
+			}, nil
+		}
+		fmt.Printf(
+			"[AzureAuth] Failed to refresh the cached token of '%s': %v\n",
+			account.PreferredUsername,
+			err,
+		)
+	}
+
+	var result public.AuthResult
+	var err error
+	if cred.isInteractive {
+		result, err = cred.signInByBrowser(ctx, options.Scopes)
+	} else {
+		result, err = cred.signInByDeviceCode(ctx, options.Scopes)
+	}
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+	return azcore.AccessToken{Token: result.AccessToken, ExpiresOn: result.ExpiresOn}, nil
+}
+
+// signInByDeviceCode asks the user to sign in on another device by a device code.
+func (cred *publicClientCredential) signInByDeviceCode(
+	ctx context.Context,
+	scopes []string,
+) (public.AuthResult, error) {
+	deviceCode, err := cred.client.AcquireTokenByDeviceCode(ctx, scopes)
+	if err != nil {
+		return public.AuthResult{}, fmt.Errorf(
+			"[AzureAuth] Failed to request a device code: %w",
+			err,
+		)
+	}
+	fmt.Println(deviceCode.Result.Message)
+
+	result, err := deviceCode.AuthenticationResult(ctx)
+	if err != nil {
+		return public.AuthResult{}, fmt.Errorf(
+			"[AzureAuth] Failed to authenticate by device code: %w",
+			err,
+		)
+	}
+	return result, nil
+}
+
+// signInByBrowser asks the user to sign in by the default browser. The browser
+// redirects to a local server on the port of the redirect URL.
+func (cred *publicClientCredential) signInByBrowser(
+	ctx context.Context,
+	scopes []string,
+) (public.AuthResult, error) {
+	fmt.Println("[AzureAuth] Sign in by the browser that has been opened.")
+	result, err := cred.client.AcquireTokenInteractive(
+		ctx,
+		scopes,
+		public.WithRedirectURI(cred.redirectURL),
+	)
+	if err != nil {
+		return public.AuthResult{}, fmt.Errorf(
+			"[AzureAuth] Failed to authenticate by browser: %w",
+			err,
+		)
+	}
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package mstodo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCache is the content of a MSAL token cache.
+type fakeCache struct {
+	content []byte
+}
+
+func (cache *fakeCache) Marshal() ([]byte, error) {
+	return cache.content, nil
+}
+
+func (cache *fakeCache) Unmarshal(content []byte) error {
+	cache.content = content
+	return nil
+}
+
+func TestTokenCache_export_isOnlyAccessibleByUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "twtodo", "token_cache.json")
+	tokenCache := &tokenCache{path: path}
+
+	tokenCache.Export(&fakeCache{content: []byte(`{"RefreshToken":{}}`)}, "")
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	loaded := &fakeCache{}
+	tokenCache.Replace(loaded, "")
+	assert.Equal(t, `{"RefreshToken":{}}`, string(loaded.content))
+}
+
+func TestTokenCache_fileMissing_isEmpty(t *testing.T) {
+	tokenCache := &tokenCache{path: filepath.Join(t.TempDir(), "token_cache.json")}
+
+	loaded := &fakeCache{}
+	tokenCache.Replace(loaded, "")
+
+	assert.Nil(t, loaded.content)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+)
+
+// account is a Microsoft account of the credentials.yaml with its authenticated client.
+type account struct {
+	name   string
+	client mstodo.ClientFacade
+	// The lists are resolved per account as each account has its own lists.
+	lists *listResolver
+}
+
+// newAccounts creates an account for each client. Key of the clients is the account
+// name.
+func newAccounts(clients map[string]mstodo.ClientFacade) map[string]*account {
+	accounts := map[string]*account{}
+	for name, client := range clients {
+		accounts[name] = &account{
+			name:   name,
+			client: client,
+			lists:  newListResolver(),
+		}
+	}
+	return accounts
+}
+
+// account returns the account with the given name. If no name is given, the only
+// account or the account 'default' is returned.
+func (h *Handler) account(name string) (*account, error) {
+	if name == "" {
+		if len(h.accounts) == 1 {
+			for _, acc := range h.accounts {
+				return acc, nil
+			}
+		}
+		name = mstodo.DefaultAccount
+	}
+
+	if acc, ok := h.accounts[name]; ok {
+		return acc, nil
+	}
+
+	names := []string{}
+	for accountName := range h.accounts {
+		names = append(names, accountName)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf(
+		"[Account] Unknown account '%s', pass one of the accounts of the "+
+			"credentials.yaml: %s",
+		name,
+		strings.Join(names, ", "),
+	)
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTwoAccountsHandler returns a handler with the accounts 'default' and 'work', which
+// both have a list 'Groceries' with another ID.
+func newTwoAccountsHandler() *Handler {
+	workClient := newFakeClient()
+	workClient.lists = []models.TaskList{
+		{ID: "id-work-groceries", DisplayName: "Groceries", WellknownListName: "none"},
+	}
+	return &Handler{accounts: newAccounts(map[string]mstodo.ClientFacade{
+		mstodo.DefaultAccount: newFakeClient(),
+		"work":                workClient,
+	})}
+}
+
+func TestAccount_noNameSingleAccount_isThatAccount(t *testing.T) {
+	handler := &Handler{accounts: newAccounts(map[string]mstodo.ClientFacade{
+		"work": newFakeClient(),
+	})}
+
+	acc, err := handler.account("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "work", acc.name)
+}
+
+func TestAccount_noNameSeveralAccounts_isDefault(t *testing.T) {
+	acc, err := newTwoAccountsHandler().account("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, mstodo.DefaultAccount, acc.name)
+}
+
+func TestAccount_unknownName_isError(t *testing.T) {
+	_, err := newTwoAccountsHandler().account("personal")
+
+	assert.ErrorContains(t, err, "Unknown account 'personal'")
+	assert.ErrorContains(t, err, "default, work")
+}
+
+func TestFindListMapping_sameListNameInOtherAccount_isNotFound(t *testing.T) {
+	handler := newTwoAccountsHandler()
+	work, _ := handler.account("work")
+	mappings := []ListMapping{
+		{List: "Groceries", Project: "home.groceries"},
+		{List: "Groceries", Account: "work", Project: "work.groceries"},
+	}
+	listID := "id-work-groceries"
+
+	mapping := handler.findListMapping(work, &listID, mappings)
+
+	assert.Equal(t, "work.groceries", mapping.Project)
+	assert.Nil(t, handler.findListMapping(work, &listID, mappings[:1]))
+}
+
+func TestOnListsRead_account_isListsOfAccount(t *testing.T) {
+	res := &Response{}
+
+	err := newTwoAccountsHandler().OnListsRead(Request{Account: "work"}, res)
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Lists, 1)
+	assert.Equal(t, "id-work-groceries", res.Lists[0].ID)
+}
@@ -5,113 +5,583 @@ import (
 	"fmt"
 	"net"
 	"net/rpc"
+	"strings"
+	"sync/atomic"
 
 	"github.com/simachri/taskwarrior-ms-todo/internal/models"
 	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+	"github.com/simachri/taskwarrior-ms-todo/internal/state"
 	"github.com/simachri/taskwarrior-ms-todo/internal/taskwarrior"
 )
 
-var TasksPullCmd = "Handler.OnTasksPull"
+var (
+	TasksPullCmd = "Handler.OnTasksPull"
+	TasksPushCmd = "Handler.OnTasksPush"
+	ListsReadCmd = "Handler.OnListsRead"
+)
 
 type Handler struct {
-	client mstodo.ClientFacade
+	// Accounts of the credentials.yaml. Key is the account name.
+	accounts map[string]*account
+	// Taskwarrior tasks that are synced with the accounts.
+	taskStore taskwarrior.TaskStore
+	store     *state.Store
+	// Number of tasks of a list that are pulled concurrently.
+	workerCount int
 }
 
 type updateStatistics struct {
 	taskCountTotal    int
 	taskCountUpdated  int32
+	taskCountPushed   int32
 	taskCountUpToDate int32
+	taskCountConflict int32
+	taskCountRemoved  int32
 	taskCountError    int32
 }
 
+type syncDirection int
+
+const (
+	SYNC_TO_TASKWARRIOR syncDirection = iota
+	SYNC_TO_TODO
+	SYNC_CONFLICT
+)
+
 type importStatistics struct {
+	pageCountFetched               int
+	taskCountFetched               int
+	taskCountCreated               int32
+	taskCountExisted               int32
+	taskCountLinkedToRecurrence    int32
+	taskCountRecurrenceUnsupported int32
+	taskCountError                 int32
+}
+
+type pushStatistics struct {
 	taskCountFetched int
 	taskCountCreated int32
-	taskCountExisted int32
 	taskCountError   int32
 }
 
+// resolveSyncDirection determines which side of a task that differs between
+// Taskwarrior and MS To-Do has to be transferred to the other side. A side has changed
+// if it has been modified after the last sync. If only one side has changed, it wins.
+// If both sides have changed, the task is in conflict. If the task has not been synced
+// yet, the side modified last wins. An error is returned if the modification date of
+// a side is missing.
+func resolveSyncDirection(
+	twTask *models.Task,
+	toDoTask *models.Task,
+	lastSync *state.TaskState,
+) (syncDirection, error) {
+	err := checkModifiedAt(twTask, toDoTask)
+	if err != nil {
+		return SYNC_CONFLICT, err
+	}
+
+	if lastSync == nil {
+		if twTask.ModifiedAt.After(*toDoTask.ModifiedAt) {
+			return SYNC_TO_TODO, nil
+		}
+		return SYNC_TO_TASKWARRIOR, nil
+	}
+
+	twChanged := twTask.ModifiedAt.After(lastSync.TaskwarriorModifiedAt)
+	toDoChanged := toDoTask.ModifiedAt.After(lastSync.ToDoModifiedAt)
+
+	switch {
+	case twChanged && toDoChanged:
+		return SYNC_CONFLICT, nil
+	case twChanged:
+		return SYNC_TO_TODO, nil
+	}
+	return SYNC_TO_TASKWARRIOR, nil
+}
+
+// checkModifiedAt returns an error if the modification date of the Taskwarrior task or
+// of the MS To-Do task is missing, as the sync cannot tell which side has changed.
+func checkModifiedAt(twTask *models.Task, toDoTask *models.Task) error {
+	if twTask.ModifiedAt == nil {
+		return errors.New("[checkModifiedAt] Taskwarrior task has no modification date.")
+	}
+	if toDoTask.ModifiedAt == nil {
+		return errors.New("[checkModifiedAt] MS To-Do task has no modification date.")
+	}
+	return nil
+}
+
+// updateTaskwarriorTasks syncs the linked tasks of a MS To-Do list in both directions.
+// Only the MS To-Do tasks that have changed since the last pull are fetched by a delta
+// query. Tasks that have not changed in MS To-Do are only read if they have changed in
+// Taskwarrior since the last sync. The tasks are synced by a pool of 'workerCount'
+// goroutines.
 func updateTaskwarriorTasks(
 	client mstodo.ClientFacade,
+	taskStore taskwarrior.TaskStore,
+	store *state.Store,
 	toDoListID *string,
+	defaults *taskwarrior.ListDefaults,
+	workerCount int,
 ) (stat *updateStatistics, err error) {
 	stat = &updateStatistics{
 		taskCountTotal:    0,
 		taskCountUpdated:  0,
+		taskCountPushed:   0,
 		taskCountUpToDate: 0,
+		taskCountConflict: 0,
+		taskCountRemoved:  0,
 		taskCountError:    0,
 	}
 
 	fmt.Println("[updateTaskWarriorTasks] Reading all imported Taskwarrior tasks.")
-	tasks, err := taskwarrior.ReadTasksAll()
+	allTasks, err := taskStore.ReadTasksAll()
 	if err != nil {
 		return stat, err
 	}
 
-	stat.taskCountTotal = len(*tasks)
+	var tasks []models.TaskwarriorTask
+	for _, task := range *allTasks {
+		if *task.ToDoListID == *toDoListID {
+			// The default tags of the list are not synced with MS To-Do categories.
+			task.Categories = withoutDefaultTags(task.Categories, defaults)
+			tasks = append(tasks, task)
+		}
+	}
 
-	for _, task := range *tasks {
-		taskFromMSToDo, err := client.ReadTaskByID(task.ToDoListID, task.ToDoTaskID)
-		if err != nil {
+	stat.taskCountTotal = len(tasks)
+
+	tasksDelta, isFullScan, err := readTasksDelta(client, store, toDoListID)
+	if err != nil {
+		return stat, err
+	}
+
+	changedTasks := map[string]*models.Task{}
+	for i := range tasksDelta.Tasks {
+		changedTasks[*tasksDelta.Tasks[i].ToDoTaskID] = &tasksDelta.Tasks[i]
+	}
+	removedTaskIDs := map[string]bool{}
+	for _, taskID := range tasksDelta.RemovedTaskIDs {
+		removedTaskIDs[taskID] = true
+	}
+	// A task that failed to be read is neither synced nor considered deleted.
+	for taskID, taskErr := range tasksDelta.TaskErrs {
+		fmt.Printf(
+			"[updateTaskWarriorTasks] Failed to read task with ID '%s': %v\n",
+			taskID,
+			taskErr,
+		)
+		stat.taskCountError++
+	}
+
+	var tasksToSync []models.TaskwarriorTask
+	var taskIDsToRead []string
+	for _, task := range tasks {
+		if task.Status == models.TW_TASKSTATUS_DELETED {
 			fmt.Printf(
-				"[updateTaskWarriorTasks] Failed to read task from MS To-Do by ID: %v\n",
-				err,
+				"[updateTaskWarriorTasks] Task is deleted in Taskwarrior: %s\n",
+				*task.Title,
 			)
-			stat.taskCountError = stat.taskCountError + 1.
 			continue
 		}
 
-		if taskFromMSToDo.IsUpToDate(&task.Task) {
-			fmt.Printf("[updateTaskWarriorTasks] Task is up to date: %s\n", *task.Title)
-			stat.taskCountUpToDate = stat.taskCountUpToDate + 1
+		if _, isFailed := tasksDelta.TaskErrs[*task.ToDoTaskID]; isFailed {
 			continue
 		}
 
-		err = taskwarrior.Update(&models.TaskwarriorTask{
-			Task:            *taskFromMSToDo,
-			TaskWarriorUUID: task.TaskWarriorUUID,
-		})
-		if err != nil {
-			fmt.Printf("[updateTaskWarriorTasks] Failed to update task: %v\n", err)
-			stat.taskCountError = stat.taskCountError + 1.
+		_, isChanged := changedTasks[*task.ToDoTaskID]
+		// A full scan returns all tasks of the list, a task that is missing has been
+		// deleted. The deletion is recorded as a delta query reports it only once.
+		if removedTaskIDs[*task.ToDoTaskID] || (isFullScan && !isChanged) ||
+			store.IsTaskRemoved(*task.ToDoTaskID) {
+			fmt.Printf(
+				"[updateTaskWarriorTasks] Task is deleted in MS To-Do: %s\n",
+				*task.Title,
+			)
+			store.SetTaskRemoved(*task.ToDoTaskID)
+			atomic.AddInt32(&stat.taskCountRemoved, 1)
 			continue
 		}
-		fmt.Printf("[updateTaskWarriorTasks] Task updated: %s\n", *task.Title)
+
+		if !isChanged {
+			taskState, ok := store.GetTaskState(*task.ToDoTaskID)
+			if ok && task.ModifiedAt != nil &&
+				!task.ModifiedAt.After(taskState.TaskwarriorModifiedAt) {
+				fmt.Printf(
+					"[updateTaskWarriorTasks] Task is up to date: %s\n",
+					*task.Title,
+				)
+				atomic.AddInt32(&stat.taskCountUpToDate, 1)
+				continue
+			}
+		}
+
+		tasksToSync = append(tasksToSync, task)
+		// The delta query does not expand the checklist items.
+		if !isChanged || taskwarrior.AreChecklistItemsSynced() {
+			taskIDsToRead = append(taskIDsToRead, *task.ToDoTaskID)
+		}
+	}
+
+	readTasks := map[string]*models.Task{}
+	readErrs := map[string]error{}
+	if len(taskIDsToRead) > 0 {
+		fmt.Printf(
+			"[updateTaskWarriorTasks] Reading %v tasks from MS To-Do by ID.\n",
+			len(taskIDsToRead),
+		)
+		readTasks, readErrs, err = client.ReadTasksByIDs(toDoListID, taskIDsToRead)
+		if err != nil {
+			return stat, err
+		}
+	}
+
+	runWorkers(workerCount, len(tasksToSync), func(index int) {
+		task := &tasksToSync[index]
+		if readErr, ok := readErrs[*task.ToDoTaskID]; ok {
+			fmt.Printf(
+				"[updateTaskWarriorTasks] Failed to read task '%s' from MS To-Do by ID: "+
+					"%v\n",
+				*task.Title,
+				readErr,
+			)
+			atomic.AddInt32(&stat.taskCountError, 1)
+			return
+		}
+
+		taskFromMSToDo, isRead := readTasks[*task.ToDoTaskID]
+		if !isRead {
+			taskFromMSToDo = changedTasks[*task.ToDoTaskID]
+		}
+		syncTask(client, taskStore, store, task, taskFromMSToDo, defaults, stat)
+	})
+
+	// If a task failed, the delta link is kept such that its changes are fetched again
+	// by the next pull.
+	if stat.taskCountError == 0 {
+		store.SetDeltaLink(*toDoListID, tasksDelta.DeltaLink)
+	}
+
+	err = store.Save()
+	if err != nil {
+		return stat, err
 	}
 
 	return stat, nil
 }
 
+// syncTask transfers a linked task that differs between Taskwarrior and MS To-Do to the
+// side determined by resolveSyncDirection and counts the result. It is safe for
+// concurrent use.
+func syncTask(
+	client mstodo.ClientFacade,
+	taskStore taskwarrior.TaskStore,
+	store *state.Store,
+	task *models.TaskwarriorTask,
+	taskFromMSToDo *models.Task,
+	defaults *taskwarrior.ListDefaults,
+	stat *updateStatistics,
+) {
+	err := checkModifiedAt(&task.Task, taskFromMSToDo)
+	if err != nil {
+		fmt.Printf("[syncTask] Failed to sync task '%s': %v\n", *task.Title, err)
+		atomic.AddInt32(&stat.taskCountError, 1)
+		return
+	}
+
+	// Categories that are not synced with Taskwarrior tags are not compared.
+	syncedTaskFromMSToDo := *taskFromMSToDo
+	syncedTaskFromMSToDo.Categories = syncedCategories(taskFromMSToDo.Categories)
+	if syncedTaskFromMSToDo.IsUpToDate(&task.Task) {
+		fmt.Printf("[syncTask] Task is up to date: %s\n", *task.Title)
+		store.SetTaskState(*task.ToDoTaskID, state.TaskState{
+			TaskwarriorModifiedAt: *task.ModifiedAt,
+			ToDoModifiedAt:        *taskFromMSToDo.ModifiedAt,
+		})
+		atomic.AddInt32(&stat.taskCountUpToDate, 1)
+		return
+	}
+
+	var lastSync *state.TaskState
+	if taskState, ok := store.GetTaskState(*task.ToDoTaskID); ok {
+		lastSync = &taskState
+	}
+
+	direction, err := resolveSyncDirection(&task.Task, taskFromMSToDo, lastSync)
+	if err != nil {
+		fmt.Printf("[syncTask] Failed to sync task '%s': %v\n", *task.Title, err)
+		atomic.AddInt32(&stat.taskCountError, 1)
+		return
+	}
+
+	switch direction {
+	case SYNC_CONFLICT:
+		fmt.Printf(
+			"[syncTask] CONFLICT - task changed in Taskwarrior and "+
+				"MS To-Do since last sync, resolve manually: %s\n",
+			*task.Title,
+		)
+		atomic.AddInt32(&stat.taskCountConflict, 1)
+		return
+
+	case SYNC_TO_TODO:
+		task.Categories = mergeCategories(task.Categories, taskFromMSToDo.Categories)
+		// A waiting task stays 'deferred' in MS To-Do.
+		task.ToDoStatus = taskFromMSToDo.ToDoStatus
+		// An unchanged note is not pushed such that a HTML body of the MS To-Do task,
+		// which is read as plain text, keeps its formatting and links.
+		if task.Note != nil && taskFromMSToDo.Note != nil &&
+			*task.Note == *taskFromMSToDo.Note {
+			task.Note = nil
+		}
+		err = pushTaskUpdate(client, store, task)
+		if err != nil {
+			fmt.Printf(
+				"[syncTask] Failed to update task in MS To-Do: %v\n",
+				err,
+			)
+			atomic.AddInt32(&stat.taskCountError, 1)
+			return
+		}
+		fmt.Printf(
+			"[syncTask] Task updated in MS To-Do: %s\n",
+			*task.Title,
+		)
+		atomic.AddInt32(&stat.taskCountPushed, 1)
+
+	case SYNC_TO_TASKWARRIOR:
+		err = pullTaskUpdate(
+			taskStore,
+			store,
+			taskFromMSToDo,
+			task.TaskWarriorUUID,
+			defaults,
+		)
+		if err != nil {
+			fmt.Printf("[syncTask] Failed to update task: %v\n", err)
+			atomic.AddInt32(&stat.taskCountError, 1)
+			return
+		}
+		fmt.Printf("[syncTask] Task updated: %s\n", *task.Title)
+		atomic.AddInt32(&stat.taskCountUpdated, 1)
+	}
+}
+
+// readTasksDelta fetches the tasks of a MS To-Do list that have changed since the delta
+// link stored for the list. If no delta link is stored or it has expired, all tasks of
+// the list are fetched, which is reported by 'isFullScan'.
+func readTasksDelta(
+	client mstodo.ClientFacade,
+	store *state.Store,
+	toDoListID *string,
+) (tasksDelta *mstodo.TasksDelta, isFullScan bool, err error) {
+	deltaLink, ok := store.GetDeltaLink(*toDoListID)
+	if ok {
+		fmt.Printf(
+			"[readTasksDelta] Fetching changed tasks from MS To-Do list '%s'...\n",
+			*toDoListID,
+		)
+		tasksDelta, err = client.ReadTasksDelta(toDoListID, &deltaLink)
+		if err == nil {
+			return tasksDelta, false, nil
+		}
+		if !errors.Is(err, mstodo.ErrDeltaTokenExpired) {
+			return nil, false, err
+		}
+		fmt.Printf(
+			"[readTasksDelta] Delta token of MS To-Do list '%s' has expired, falling "+
+				"back to a full scan.\n",
+			*toDoListID,
+		)
+		store.SetDeltaLink(*toDoListID, "")
+	}
+
+	fmt.Printf(
+		"[readTasksDelta] Fetching all tasks from MS To-Do list '%s'...\n",
+		*toDoListID,
+	)
+	tasksDelta, err = client.ReadTasksDelta(toDoListID, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	return tasksDelta, true, nil
+}
+
+// withoutDefaultTags removes the default tags of a list from the categories of a
+// Taskwarrior task.
+func withoutDefaultTags(categories []string, defaults *taskwarrior.ListDefaults) []string {
+	if categories == nil || defaults == nil {
+		return categories
+	}
+
+	filtered := []string{}
+	for _, category := range categories {
+		isDefaultTag := false
+		for _, tag := range defaults.Tags {
+			if category == tag {
+				isDefaultTag = true
+				break
+			}
+		}
+		if !isDefaultTag {
+			filtered = append(filtered, category)
+		}
+	}
+	return filtered
+}
+
+// syncedCategories returns the MS To-Do categories that are synced with Taskwarrior
+// tags.
+func syncedCategories(categories []string) []string {
+	if categories == nil {
+		return nil
+	}
+
+	synced := []string{}
+	for _, category := range categories {
+		if taskwarrior.IsCategorySynced(category) {
+			synced = append(synced, category)
+		}
+	}
+	return synced
+}
+
+// mergeCategories returns the categories of a Taskwarrior task that is transferred to
+// MS To-Do: The categories that are not synced with Taskwarrior tags are kept and the
+// MS To-Do spelling of categories that only differ by their normalization is retained.
+func mergeCategories(twCategories []string, toDoCategories []string) []string {
+	if twCategories == nil {
+		return nil
+	}
+
+	merged := []string{}
+	for _, twCategory := range twCategories {
+		category := twCategory
+		for _, toDoCategory := range toDoCategories {
+			if models.NormalizeTag(toDoCategory) == models.NormalizeTag(twCategory) {
+				category = toDoCategory
+				break
+			}
+		}
+		merged = append(merged, category)
+	}
+	for _, toDoCategory := range toDoCategories {
+		if !taskwarrior.IsCategorySynced(toDoCategory) {
+			merged = append(merged, toDoCategory)
+		}
+	}
+	return merged
+}
+
+// pushTaskUpdate transfers a Taskwarrior task to MS To-Do and records the sync.
+func pushTaskUpdate(
+	client mstodo.ClientFacade,
+	store *state.Store,
+	task *models.TaskwarriorTask,
+) error {
+	err := client.UpdateTask(&task.Task)
+	if err != nil {
+		return err
+	}
+
+	updatedTask, err := client.ReadTaskByID(task.ToDoListID, task.ToDoTaskID)
+	if err != nil {
+		return err
+	}
+	err = checkModifiedAt(&task.Task, updatedTask)
+	if err != nil {
+		return err
+	}
+
+	store.SetTaskState(*task.ToDoTaskID, state.TaskState{
+		TaskwarriorModifiedAt: *task.ModifiedAt,
+		ToDoModifiedAt:        *updatedTask.ModifiedAt,
+	})
+	return nil
+}
+
+// pullTaskUpdate transfers a MS To-Do task to Taskwarrior and records the sync.
+func pullTaskUpdate(
+	taskStore taskwarrior.TaskStore,
+	store *state.Store,
+	task *models.Task,
+	taskwarriorUUID *string,
+	defaults *taskwarrior.ListDefaults,
+) error {
+	err := taskStore.Update(&models.TaskwarriorTask{
+		Task:            *task,
+		TaskWarriorUUID: taskwarriorUUID,
+	}, defaults)
+	if err != nil {
+		return err
+	}
+
+	updatedTask, err := taskStore.ReadTaskByUUID(taskwarriorUUID)
+	if err != nil {
+		return err
+	}
+	err = checkModifiedAt(&updatedTask.Task, task)
+	if err != nil {
+		return err
+	}
+
+	store.SetTaskState(*task.ToDoTaskID, state.TaskState{
+		TaskwarriorModifiedAt: *updatedTask.ModifiedAt,
+		ToDoModifiedAt:        *task.ModifiedAt,
+	})
+	return nil
+}
+
+// importOpenTasks creates Taskwarrior tasks for the open tasks of a MS To-Do list that
+// are not yet linked. The tasks are imported by a pool of 'workerCount' goroutines.
 func importOpenTasks(
 	client mstodo.ClientFacade,
+	taskStore taskwarrior.TaskStore,
 	toDoListID *string,
+	defaults *taskwarrior.ListDefaults,
+	workerCount int,
 ) (stat *importStatistics, err error) {
 	stat = &importStatistics{
-		taskCountFetched: 0,
-		taskCountCreated: 0,
-		taskCountExisted: 0,
-		taskCountError:   0,
+		pageCountFetched:               0,
+		taskCountFetched:               0,
+		taskCountCreated:               0,
+		taskCountExisted:               0,
+		taskCountLinkedToRecurrence:    0,
+		taskCountRecurrenceUnsupported: 0,
+		taskCountError:                 0,
 	}
 
 	fmt.Printf(
 		"[importOpenTasks] Fetching tasks from MS To-Do list '%s'...\n",
 		*toDoListID,
 	)
-	tasks, err := client.ReadOpenTasks(toDoListID)
+	tasks, taskErrs, pageCount, err := client.ReadOpenTasks(toDoListID)
+	stat.pageCountFetched = pageCount
 	if err != nil {
 		return stat, err
 	}
 
-	stat.taskCountFetched = len(*tasks)
-
-	for _, task := range *tasks {
-		fmt.Println("[importOpenTasks] Start import of task...")
+	stat.taskCountFetched = len(*tasks) + len(taskErrs)
+	for taskID, taskErr := range taskErrs {
+		fmt.Printf(
+			"[importOpenTasks] ERROR - failed to read task with ID '%s': %v\n",
+			taskID,
+			taskErr,
+		)
+		stat.taskCountError++
+	}
 
-		result, err := taskwarrior.Import(&task)
+	runWorkers(workerCount, len(*tasks), func(index int) {
+		task := &(*tasks)[index]
+		result, err := taskStore.Import(task, defaults)
 		if err != nil {
-			fmt.Printf("[importOpenTasks] Error: %v", err)
-			stat.taskCountError = stat.taskCountError + 1
-			continue
+			fmt.Printf(
+				"[importOpenTasks] ERROR - failed to import task '%s': %v\n",
+				*task.Title,
+				err,
+			)
+			atomic.AddInt32(&stat.taskCountError, 1)
+			return
 		}
 
 		switch result {
@@ -120,18 +590,37 @@ func importOpenTasks(
 				"[importOpenTasks] NEW - Taskwarrior task created: '%s'\n",
 				*task.Title,
 			)
-			stat.taskCountCreated = stat.taskCountCreated + 1
-			continue
+			atomic.AddInt32(&stat.taskCountCreated, 1)
+			return
 
 		case taskwarrior.TASK_EXISTS_AND_SKIPPED:
 			fmt.Printf(
 				"[importOpenTasks] SKIP - task already exists in Taskwarrior: '%s'\n",
 				*task.Title,
 			)
-			stat.taskCountExisted = stat.taskCountExisted + 1
-			continue
+			atomic.AddInt32(&stat.taskCountExisted, 1)
+			return
+
+		case taskwarrior.TASK_LINKED_TO_RECURRENCE:
+			fmt.Printf(
+				"[importOpenTasks] LINK - occurrence linked to recurring Taskwarrior "+
+					"task: '%s'\n",
+				*task.Title,
+			)
+			atomic.AddInt32(&stat.taskCountLinkedToRecurrence, 1)
+			return
+
+		case taskwarrior.TASK_CREATED_WITHOUT_RECURRENCE:
+			fmt.Printf(
+				"[importOpenTasks] NEW - Taskwarrior task created WITHOUT recurrence, "+
+					"the recurrence pattern is not supported: '%s'\n",
+				*task.Title,
+			)
+			atomic.AddInt32(&stat.taskCountCreated, 1)
+			atomic.AddInt32(&stat.taskCountRecurrenceUnsupported, 1)
+			return
 		}
-	}
+	})
 
 	return stat, nil
 }
@@ -139,43 +628,330 @@ func importOpenTasks(
 func (h *Handler) OnTasksPull(req Request, res *Response) error {
 	fmt.Println("[OnTasksPull] Handling 'pull' command...")
 
-	updateStat, err := updateTaskwarriorTasks(h.client, &req.ListID)
+	if req.ListID != "" {
+		acc, err := h.account(req.Account)
+		if err != nil {
+			return err
+		}
+
+		listID, err := acc.lists.resolve(acc.client, req.ListID)
+		if err != nil {
+			return err
+		}
+
+		mapping := h.findListMapping(acc, &listID, req.Lists)
+		if mapping == nil {
+			mapping = &ListMapping{List: listID, Account: acc.name}
+		}
+
+		message, err := h.pullList(acc, &listID, mapping)
+		if err != nil {
+			return err
+		}
+		res.Message = "[OnTasksPull] Pull succesful:\n" + message
+
+		fmt.Println("[OnTasksPull] 'pull' command finished.")
+		return nil
+	}
+
+	mappings := append([]ListMapping{}, req.Lists...)
+	// The lists created for Taskwarrior projects are pulled as well.
+	for listID, project := range h.store.GetProjectLists() {
+		accountName, _ := h.store.GetListAccount(listID)
+		acc, err := h.account(accountName)
+		if err == nil && h.findListMapping(acc, &listID, req.Lists) != nil {
+			continue
+		}
+		mappings = append(
+			mappings,
+			ListMapping{List: listID, Project: project, Account: accountName},
+		)
+	}
+
+	// If an account is given, only its lists are pulled.
+	var reqAcc *account
+	if req.Account != "" {
+		var err error
+		reqAcc, err = h.account(req.Account)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(mappings) == 0 {
+		return errors.New("[OnTasksPull] No MS To-Do list given. Pass a list or " +
+			"configure the lists to sync in 'sync.lists' of the config.yaml.")
+	}
+
+	res.Message = "[OnTasksPull] Pull finished:"
+	for _, mapping := range mappings {
+		acc, err := h.account(mapping.Account)
+		if reqAcc != nil && acc != reqAcc {
+			continue
+		}
+
+		if mapping.Account != "" {
+			res.Message += fmt.Sprintf(
+				"\n  [List] '%s' (account '%s', project '%s'):\n",
+				mapping.List,
+				mapping.Account,
+				mapping.Project,
+			)
+		} else {
+			res.Message += fmt.Sprintf(
+				"\n  [List] '%s' (project '%s'):\n",
+				mapping.List,
+				mapping.Project,
+			)
+		}
+		if err != nil {
+			res.Message += fmt.Sprintf("    Error: %v", err)
+			continue
+		}
+
+		listID, err := acc.lists.resolve(acc.client, mapping.List)
+		if err != nil {
+			res.Message += fmt.Sprintf("    Error: %v", err)
+			continue
+		}
+
+		message, err := h.pullList(acc, &listID, &mapping)
+		if err != nil {
+			res.Message += fmt.Sprintf("    Error: %v", err)
+			continue
+		}
+		res.Message += message
+	}
+
+	fmt.Println("[OnTasksPull] 'pull' command finished.")
+	return nil
+}
+
+// findListMapping returns the mapping of the config 'sync.lists' for the given list of
+// the given account. 'nil' is returned if the list is not configured.
+func (h *Handler) findListMapping(
+	acc *account,
+	listID *string,
+	mappings []ListMapping,
+) *ListMapping {
+	for _, mapping := range mappings {
+		mappedAcc, err := h.account(mapping.Account)
+		if err != nil || mappedAcc != acc {
+			continue
+		}
+		mappedListID, err := acc.lists.resolve(acc.client, mapping.List)
+		if err == nil && mappedListID == *listID {
+			return &mapping
+		}
+	}
+	return nil
+}
+
+// pullList syncs the tasks of a MS To-Do list of an account and returns the statistics.
+func (h *Handler) pullList(
+	acc *account,
+	listID *string,
+	mapping *ListMapping,
+) (string, error) {
+	throttleStatBefore := acc.client.ReadThrottleStatistics()
+
+	updateStat, err := updateTaskwarriorTasks(
+		acc.client,
+		h.taskStore,
+		h.store,
+		listID,
+		mapping.defaults(),
+		h.workerCount,
+	)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	importStat, err := importOpenTasks(h.client, &req.ListID)
+	importStat, err := importOpenTasks(
+		acc.client,
+		h.taskStore,
+		listID,
+		mapping.defaults(),
+		h.workerCount,
+	)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	res.Message = fmt.Sprintf(
-		"[OnTasksPull] Pull succesful:\n"+
-			"    [Update] MS To-Do tasks existing in Taskwarrior: %v\n"+
+	throttleStat := acc.client.ReadThrottleStatistics()
+
+	return fmt.Sprintf(
+		"    [Update] MS To-Do tasks existing in Taskwarrior: %v\n"+
 			"    [Update] Taskwarrior tasks up-to-date: %v\n"+
 			"    [Update] Taskwarrior tasks updated: %v\n"+
+			"    [Update] MS To-Do tasks updated: %v\n"+
+			"    [Update] Conflicts (changed on both sides): %v\n"+
+			"    [Update] Tasks deleted in MS To-Do: %v\n"+
 			"    [Update] Errors: %v\n"+
+			"    [Import] Pages fetched from MS To-Do: %v\n"+
 			"    [Import] Open Tasks fetched from MS To-Do: %v\n"+
 			"    [Import] New Tasks created in Taskwarrior: %v\n"+
 			"    [Import] Tasks already existed in Taskwarrior: %v\n"+
-			"    [Import] Errors: %v",
+			"    [Import] Occurrences linked to recurring Taskwarrior tasks: %v\n"+
+			"    [Import] Tasks with unsupported recurrence (created without): %v\n"+
+			"    [Import] Errors: %v\n"+
+			"    [Throttle] Requests throttled by MS To-Do: %v\n"+
+			"    [Throttle] Requests retried: %v\n"+
+			"    [Throttle] Requests failed after all retries: %v",
 		updateStat.taskCountTotal,
 		updateStat.taskCountUpToDate,
-		updateStat.taskCountUpToDate,
+		updateStat.taskCountUpdated,
+		updateStat.taskCountPushed,
+		updateStat.taskCountConflict,
+		updateStat.taskCountRemoved,
 		updateStat.taskCountError,
+		importStat.pageCountFetched,
 		importStat.taskCountFetched,
 		importStat.taskCountCreated,
 		importStat.taskCountExisted,
+		importStat.taskCountLinkedToRecurrence,
+		importStat.taskCountRecurrenceUnsupported,
 		importStat.taskCountError,
+		throttleStat.ThrottledCount-throttleStatBefore.ThrottledCount,
+		throttleStat.RetryCount-throttleStatBefore.RetryCount,
+		throttleStat.GaveUpCount-throttleStatBefore.GaveUpCount,
+	), nil
+}
+
+func pushTasks(
+	client mstodo.ClientFacade,
+	taskStore taskwarrior.TaskStore,
+	toDoListID *string,
+	filter *string,
+) (stat *pushStatistics, err error) {
+	stat = &pushStatistics{
+		taskCountFetched: 0,
+		taskCountCreated: 0,
+		taskCountError:   0,
+	}
+
+	fmt.Printf(
+		"[pushTasks] Reading Taskwarrior tasks not yet linked to MS To-Do, filter: '%s'\n",
+		*filter,
 	)
+	tasks, err := taskStore.ReadTasksUnlinked(filter)
+	if err != nil {
+		return stat, err
+	}
 
-	fmt.Println("[OnTasksPull] 'pull' command finished.")
+	stat.taskCountFetched = len(*tasks)
+
+	for _, task := range *tasks {
+		createdTask, err := client.CreateTask(toDoListID, &task.Task)
+		if err != nil {
+			fmt.Printf("[pushTasks] Error: %v", err)
+			stat.taskCountError = stat.taskCountError + 1
+			continue
+		}
+
+		err = taskStore.Link(&models.TaskwarriorTask{
+			Task:            *createdTask,
+			TaskWarriorUUID: task.TaskWarriorUUID,
+		})
+		if err != nil {
+			fmt.Printf("[pushTasks] Failed to link Taskwarrior task: %v\n", err)
+			stat.taskCountError = stat.taskCountError + 1
+			continue
+		}
+
+		fmt.Printf(
+			"[pushTasks] NEW - MS To-Do task created: '%s'\n",
+			*task.Title,
+		)
+		stat.taskCountCreated = stat.taskCountCreated + 1
+	}
+
+	return stat, nil
+}
+
+func (h *Handler) OnTasksPush(req Request, res *Response) error {
+	fmt.Println("[OnTasksPush] Handling 'push' command...")
+
+	acc, err := h.account(req.Account)
+	if err != nil {
+		return err
+	}
+
+	if req.ListID == "" && len(req.Projects) > 0 {
+		message, err := pushProjects(
+			acc,
+			h.taskStore,
+			h.store,
+			req.Projects,
+			&req.Filter,
+		)
+		if err != nil {
+			return err
+		}
+		res.Message = "[OnTasksPush] Push finished:" + message
+
+		fmt.Println("[OnTasksPush] 'push' command finished.")
+		return nil
+	}
+
+	listID, err := acc.lists.resolve(acc.client, req.ListID)
+	if err != nil {
+		return err
+	}
+
+	pushStat, err := pushTasks(acc.client, h.taskStore, &listID, &req.Filter)
+	if err != nil {
+		return err
+	}
+
+	res.Message = fmt.Sprintf(
+		"[OnTasksPush] Push succesful:\n"+
+			"    [Push] Taskwarrior tasks not yet in MS To-Do: %v\n"+
+			"    [Push] New Tasks created in MS To-Do: %v\n"+
+			"    [Push] Errors: %v",
+		pushStat.taskCountFetched,
+		pushStat.taskCountCreated,
+		pushStat.taskCountError,
+	)
+
+	fmt.Println("[OnTasksPush] 'push' command finished.")
+	return nil
+}
+
+// OnListsRead returns the MS To-Do lists of the authenticated user of an account.
+func (h *Handler) OnListsRead(req Request, res *Response) error {
+	fmt.Println("[OnListsRead] Handling 'lists' command...")
+
+	acc, err := h.account(req.Account)
+	if err != nil {
+		return err
+	}
+
+	lists, err := acc.client.ReadLists()
+	if err != nil {
+		return err
+	}
+	res.Lists = *lists
+
+	fmt.Println("[OnListsRead] 'lists' command finished.")
 	return nil
 }
 
-// Start starts the server to handle commands from the CLI.
-func Start(client mstodo.ClientFacade, port *int32) error {
-	rpc.Register(&Handler{client: client})
+// Start starts the server to handle commands from the CLI. 'clients' are the
+// authenticated clients of the accounts, key is the account name. 'workerCount' is the
+// number of tasks of a list that are pulled concurrently.
+func Start(
+	clients map[string]mstodo.ClientFacade,
+	store *state.Store,
+	port *int32,
+	workerCount int,
+) error {
+	rpc.Register(&Handler{
+		accounts:    newAccounts(clients),
+		taskStore:   taskwarrior.CLIStore{},
+		store:       store,
+		workerCount: workerCount,
+	})
 
 	fmt.Println("[Server] Starting...")
 
@@ -204,18 +980,26 @@ func checkHealth() error {
 		return errors.New(fmt.Sprintf("[healthCheck] The following Taskwarrior "+
 			"User-Defined-Attributes have to exist. Create them by running the command "+
 			"'twtodo setup'.\n"+
-			"              %s\n"+
 			"              %s\n",
-			models.UDANameTodoListID,
-			models.UDANameTodoTaskID))
+			strings.Join(requiredUDAs(), "\n              ")))
 	}
 
 	return nil
 }
 
+// requiredUDAs returns the names of the Taskwarrior UDAs the sync requires. The UDA of
+// the checklist items is only required if they are synced such that an installation
+// without it keeps working.
+func requiredUDAs() []string {
+	udas := []string{models.UDANameTodoListID, models.UDANameTodoTaskID}
+	if taskwarrior.AreChecklistItemsSynced() {
+		udas = append(udas, models.UDANameTodoChecklistItemID)
+	}
+	return udas
+}
+
 func udasExist() bool {
-	udas := [2]string{models.UDANameTodoListID, models.UDANameTodoTaskID}
-	for _, udaName := range udas {
+	for _, udaName := range requiredUDAs() {
 		if exists, _ := taskwarrior.UDAExists(udaName); !exists {
 			return false
 		}
@@ -1,8 +1,14 @@
 package server
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+	"github.com/simachri/taskwarrior-ms-todo/internal/state"
 	"github.com/simachri/taskwarrior-ms-todo/internal/taskwarrior"
 	"github.com/simachri/taskwarrior-ms-todo/internal/test"
 	"github.com/stretchr/testify/assert"
@@ -25,3 +31,540 @@ func TestCheckHealth_udasMissing_isError(t *testing.T) {
     assert.Error(t, err)
 }
 
+func TestCheckHealth_checklistItemsNotSynced_checklistItemUDANotRequired(t *testing.T) {
+	test.NewTaskwarriorEnv(t)
+	err := taskwarrior.CreateUDA(models.UDANameTodoListID, "MS To-Do List ID")
+	assert.NoError(t, err)
+	err = taskwarrior.CreateUDA(models.UDANameTodoTaskID, "MS To-Do Task ID")
+	assert.NoError(t, err)
+
+	err = checkHealth()
+	assert.NoError(t, err)
+
+	twConfig := taskwarrior.DefaultConfig()
+	twConfig.ChecklistItems = true
+	taskwarrior.Configure(twConfig)
+	defer taskwarrior.Configure(taskwarrior.DefaultConfig())
+	err = checkHealth()
+	assert.ErrorContains(t, err, models.UDANameTodoChecklistItemID)
+}
+
+func TestRequiredUDAs_checklistItemUDAOnlyIfSynced(t *testing.T) {
+	assert.Equal(t,
+		[]string{models.UDANameTodoListID, models.UDANameTodoTaskID},
+		requiredUDAs(),
+	)
+
+	twConfig := taskwarrior.DefaultConfig()
+	twConfig.ChecklistItems = true
+	taskwarrior.Configure(twConfig)
+	defer taskwarrior.Configure(taskwarrior.DefaultConfig())
+	assert.Contains(t, requiredUDAs(), models.UDANameTodoChecklistItemID)
+}
+
+func newTaskModifiedAt(modifiedAt time.Time) *models.Task {
+	return &models.Task{ModifiedAt: &modifiedAt}
+}
+
+func TestResolveSyncDirection_notSyncedYet_newerWins(t *testing.T) {
+	older := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	direction, err := resolveSyncDirection(
+		newTaskModifiedAt(newer),
+		newTaskModifiedAt(older),
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, SYNC_TO_TODO, direction)
+
+	direction, err = resolveSyncDirection(
+		newTaskModifiedAt(older),
+		newTaskModifiedAt(newer),
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, SYNC_TO_TASKWARRIOR, direction)
+}
+
+func TestResolveSyncDirection_onlyTaskwarriorChanged_isToToDo(t *testing.T) {
+	syncedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	lastSync := &state.TaskState{
+		TaskwarriorModifiedAt: syncedAt,
+		ToDoModifiedAt:        syncedAt,
+	}
+
+	direction, err := resolveSyncDirection(
+		newTaskModifiedAt(syncedAt.Add(time.Minute)),
+		newTaskModifiedAt(syncedAt),
+		lastSync,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, SYNC_TO_TODO, direction)
+}
+
+func TestResolveSyncDirection_onlyToDoChanged_isToTaskwarrior(t *testing.T) {
+	syncedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	lastSync := &state.TaskState{
+		TaskwarriorModifiedAt: syncedAt,
+		ToDoModifiedAt:        syncedAt,
+	}
+
+	direction, err := resolveSyncDirection(
+		newTaskModifiedAt(syncedAt),
+		newTaskModifiedAt(syncedAt.Add(time.Minute)),
+		lastSync,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, SYNC_TO_TASKWARRIOR, direction)
+}
+
+func TestResolveSyncDirection_bothChanged_isConflict(t *testing.T) {
+	syncedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	lastSync := &state.TaskState{
+		TaskwarriorModifiedAt: syncedAt,
+		ToDoModifiedAt:        syncedAt,
+	}
+
+	direction, err := resolveSyncDirection(
+		newTaskModifiedAt(syncedAt.Add(time.Minute)),
+		newTaskModifiedAt(syncedAt.Add(time.Hour)),
+		lastSync,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, SYNC_CONFLICT, direction)
+}
+
+func TestResolveSyncDirection_modificationDateMissing_isError(t *testing.T) {
+	modifiedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := resolveSyncDirection(newTaskModifiedAt(modifiedAt), &models.Task{}, nil)
+	assert.ErrorContains(t, err, "MS To-Do task has no modification date")
+
+	_, err = resolveSyncDirection(&models.Task{}, newTaskModifiedAt(modifiedAt), nil)
+	assert.ErrorContains(t, err, "Taskwarrior task has no modification date")
+}
+
+func TestMergeCategories_unsyncedAndSpellingAreKept(t *testing.T) {
+	twConfig := taskwarrior.DefaultConfig()
+	twConfig.Tags.Allowlist = []string{"Red_category", "work"}
+	taskwarrior.Configure(twConfig)
+	defer taskwarrior.Configure(taskwarrior.DefaultConfig())
+
+	merged := mergeCategories(
+		[]string{"Red_category", "work"},
+		[]string{"Red category", "Blue category"},
+	)
+
+	assert.ElementsMatch(t, []string{"Red category", "work", "Blue category"}, merged)
+	assert.Equal(t, []string{"Red category"},
+		syncedCategories([]string{"Red category", "Blue category"}))
+}
+
+func TestWithoutDefaultTags(t *testing.T) {
+	defaults := &taskwarrior.ListDefaults{Tags: []string{"shopping"}}
+
+	assert.Equal(t, []string{"work"},
+		withoutDefaultTags([]string{"shopping", "work"}, defaults))
+	assert.Nil(t, withoutDefaultTags(nil, defaults))
+	assert.Equal(t, []string{"work"}, withoutDefaultTags([]string{"work"}, nil))
+}
+
+func TestOnTasksPull_noListGiven_isError(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	handler := &Handler{
+		accounts: newAccounts(map[string]mstodo.ClientFacade{
+			mstodo.DefaultAccount: newFakeClient(),
+		}),
+		store: store,
+	}
+
+	err = handler.OnTasksPull(Request{}, &Response{})
+
+	assert.ErrorContains(t, err, "No MS To-Do list given")
+}
+
+func TestReadTasksDelta_noDeltaLink_isFullScan(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	client := newFakeClient()
+	listID := "id-groceries"
+
+	tasksDelta, isFullScan, err := readTasksDelta(client, store, &listID)
+
+	assert.NoError(t, err)
+	assert.True(t, isFullScan)
+	assert.Equal(t, "delta-id-groceries", tasksDelta.DeltaLink)
+	assert.Equal(t, []string{""}, client.deltaLinks)
+}
+
+func TestReadTasksDelta_deltaLinkStored_isIncremental(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	client := newFakeClient()
+	listID := "id-groceries"
+	store.SetDeltaLink(listID, "delta-1")
+
+	_, isFullScan, err := readTasksDelta(client, store, &listID)
+
+	assert.NoError(t, err)
+	assert.False(t, isFullScan)
+	assert.Equal(t, []string{"delta-1"}, client.deltaLinks)
+}
+
+func TestReadTasksDelta_deltaLinkExpired_isFullScan(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	client := newFakeClient()
+	client.expiredDeltaLink = "delta-1"
+	listID := "id-groceries"
+	store.SetDeltaLink(listID, "delta-1")
+
+	tasksDelta, isFullScan, err := readTasksDelta(client, store, &listID)
+
+	assert.NoError(t, err)
+	assert.True(t, isFullScan)
+	assert.Equal(t, "delta-id-groceries", tasksDelta.DeltaLink)
+	assert.Equal(t, []string{"delta-1", ""}, client.deltaLinks)
+	_, ok := store.GetDeltaLink(listID)
+	assert.False(t, ok, "The expired delta link is not removed.")
+}
+
+func TestImportOpenTasks_createdOnceWithListDefaults(t *testing.T) {
+	listID := "id-groceries"
+	client := newFakeClient()
+	client.addTask(listID, "task-1", "Milk", time.Now())
+	client.addTask(listID, "task-2", "Bread", time.Now())
+	client.addTask(listID, "task-3", "Eggs", time.Now()).Status = models.TW_TASKSTATUS_COMPLETED
+	taskStore := taskwarrior.NewMemoryStore()
+	defaults := &taskwarrior.ListDefaults{Project: "home"}
+
+	stat, err := importOpenTasks(client, taskStore, &listID, defaults, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stat.taskCountFetched)
+	assert.Equal(t, int32(2), stat.taskCountCreated)
+
+	stat, err = importOpenTasks(client, taskStore, &listID, defaults, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), stat.taskCountCreated)
+	assert.Equal(t, int32(2), stat.taskCountExisted)
+	projects, err := taskStore.ReadProjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"home"}, projects)
+}
+
+func TestUpdateTaskwarriorTasks_changedInToDo_isPulled(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	listID := "id-groceries"
+	client := newFakeClient()
+	milk := client.addTask(listID, "task-1", "Milk", time.Now())
+	client.addTask(listID, "task-2", "Bread", time.Now())
+	taskStore := taskwarrior.NewMemoryStore()
+	_, err = importOpenTasks(client, taskStore, &listID, nil, 1)
+	assert.NoError(t, err)
+
+	oatMilk := "Oat milk"
+	milk.Title = &oatMilk
+	modifiedAt := time.Now().Add(time.Hour)
+	milk.ModifiedAt = &modifiedAt
+	delete(client.tasks, "task-2")
+	client.removedTaskIDs = []string{"task-2"}
+
+	stat, err := updateTaskwarriorTasks(client, taskStore, store, &listID, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stat.taskCountTotal)
+	assert.Equal(t, int32(1), stat.taskCountUpdated)
+	assert.Equal(t, int32(1), stat.taskCountRemoved)
+	assert.Empty(t, client.updatedTasks)
+	tasks, err := taskStore.ReadTasksAll()
+	if assert.NoError(t, err) {
+		titles := []string{}
+		for _, task := range *tasks {
+			titles = append(titles, *task.Title)
+		}
+		assert.ElementsMatch(t, []string{"Oat milk", "Bread"}, titles)
+	}
+	deltaLink, _ := store.GetDeltaLink(listID)
+	assert.Equal(t, "delta-id-groceries", deltaLink)
+	_, ok := store.GetTaskState("task-1")
+	assert.True(t, ok, "The sync of the task is not recorded.")
+}
+
+func TestUpdateTaskwarriorTasks_removedInToDoAndModifiedLater_isNotRead(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	listID := "id-groceries"
+	client := newFakeClient()
+	client.addTask(listID, "task-1", "Milk", time.Now())
+	taskStore := taskwarrior.NewMemoryStore()
+	_, err = importOpenTasks(client, taskStore, &listID, nil, 1)
+	assert.NoError(t, err)
+	delete(client.tasks, "task-1")
+	client.removedTaskIDs = []string{"task-1"}
+	_, err = updateTaskwarriorTasks(client, taskStore, store, &listID, nil, 1)
+	assert.NoError(t, err)
+
+	// The next delta query does not report the deleted task again.
+	client.removedTaskIDs = nil
+	store.SetDeltaLink(listID, "delta-outdated")
+	tasks, err := taskStore.ReadTasksAll()
+	if assert.NoError(t, err) && assert.Len(t, *tasks, 1) {
+		err = taskStore.ModifyTask(*(*tasks)[0].TaskWarriorUUID, func(task *models.Task) {
+			oatMilk := "Oat milk"
+			task.Title = &oatMilk
+		})
+		assert.NoError(t, err)
+	}
+
+	stat, err := updateTaskwarriorTasks(client, taskStore, store, &listID, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), stat.taskCountError)
+	assert.Equal(t, int32(1), stat.taskCountRemoved)
+	assert.Empty(t, client.updatedTasks)
+	deltaLink, _ := store.GetDeltaLink(listID)
+	assert.Equal(t, "delta-id-groceries", deltaLink)
+}
+
+func TestUpdateTaskwarriorTasks_changedInTaskwarrior_isPushed(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	listID := "id-groceries"
+	client := newFakeClient()
+	syncedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	client.addTask(listID, "task-1", "Milk", syncedAt)
+	taskStore := taskwarrior.NewMemoryStore()
+	_, err = importOpenTasks(client, taskStore, &listID, nil, 1)
+	assert.NoError(t, err)
+	store.SetTaskState("task-1", state.TaskState{
+		TaskwarriorModifiedAt: syncedAt,
+		ToDoModifiedAt:        syncedAt,
+	})
+
+	tasks, err := taskStore.ReadTasksAll()
+	if assert.NoError(t, err) && assert.Len(t, *tasks, 1) {
+		err = taskStore.ModifyTask(*(*tasks)[0].TaskWarriorUUID, func(task *models.Task) {
+			oatMilk := "Oat milk"
+			task.Title = &oatMilk
+		})
+		assert.NoError(t, err)
+	}
+
+	stat, err := updateTaskwarriorTasks(client, taskStore, store, &listID, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), stat.taskCountPushed)
+	assert.Equal(t, int32(0), stat.taskCountUpdated)
+	if assert.Len(t, client.updatedTasks, 1) {
+		assert.Equal(t, "Oat milk", *client.updatedTasks[0].Title)
+	}
+}
+
+func TestUpdateTaskwarriorTasks_deferredTaskChangedInTaskwarrior_staysDeferred(
+	t *testing.T,
+) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	listID := "id-groceries"
+	client := newFakeClient()
+	syncedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	milk := client.addTask(listID, "task-1", "Milk", syncedAt)
+	deferred := models.TODO_TASKSTATUS_DEFERRED
+	milk.Status = models.TW_TASKSTATUS_WAITING
+	milk.ToDoStatus = &deferred
+	taskStore := taskwarrior.NewMemoryStore()
+	_, err = importOpenTasks(client, taskStore, &listID, nil, 1)
+	assert.NoError(t, err)
+	store.SetTaskState("task-1", state.TaskState{
+		TaskwarriorModifiedAt: syncedAt,
+		ToDoModifiedAt:        syncedAt,
+	})
+
+	tasks, err := taskStore.ReadTasksAll()
+	if assert.NoError(t, err) && assert.Len(t, *tasks, 1) {
+		err = taskStore.ModifyTask(*(*tasks)[0].TaskWarriorUUID, func(task *models.Task) {
+			oatMilk := "Oat milk"
+			task.Title = &oatMilk
+			task.ToDoStatus = nil
+		})
+		assert.NoError(t, err)
+	}
+
+	stat, err := updateTaskwarriorTasks(client, taskStore, store, &listID, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), stat.taskCountPushed)
+	if assert.Len(t, client.updatedTasks, 1) {
+		toDoStatus, err := models.ConvStatusToToDo(
+			client.updatedTasks[0].Status,
+			client.updatedTasks[0].ToDoStatus,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, models.TODO_TASKSTATUS_DEFERRED, toDoStatus)
+	}
+}
+
+func TestUpdateTaskwarriorTasks_noteUnchanged_isNotPushed(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	listID := "id-groceries"
+	client := newFakeClient()
+	syncedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	note := "Buy at the farmers market"
+	client.addTask(listID, "task-1", "Milk", syncedAt).Note = &note
+	client.addTask(listID, "task-2", "Bread", syncedAt).Note = &note
+	taskStore := taskwarrior.NewMemoryStore()
+	_, err = importOpenTasks(client, taskStore, &listID, nil, 1)
+	assert.NoError(t, err)
+	for _, taskID := range []string{"task-1", "task-2"} {
+		store.SetTaskState(taskID, state.TaskState{
+			TaskwarriorModifiedAt: syncedAt,
+			ToDoModifiedAt:        syncedAt,
+		})
+	}
+
+	tasks, err := taskStore.ReadTasksAll()
+	if assert.NoError(t, err) && assert.Len(t, *tasks, 2) {
+		for _, task := range *tasks {
+			isMilk := *task.ToDoTaskID == "task-1"
+			err = taskStore.ModifyTask(*task.TaskWarriorUUID, func(task *models.Task) {
+				if isMilk {
+					title := "Oat milk"
+					task.Title = &title
+					return
+				}
+				changedNote := "Buy at the bakery"
+				task.Note = &changedNote
+			})
+			assert.NoError(t, err)
+		}
+	}
+
+	stat, err := updateTaskwarriorTasks(client, taskStore, store, &listID, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), stat.taskCountPushed)
+	if assert.Len(t, client.updatedTasks, 2) {
+		for _, task := range client.updatedTasks {
+			if *task.ToDoTaskID == "task-1" {
+				assert.Nil(t, task.Note, "The unchanged note is pushed.")
+			} else if assert.NotNil(t, task.Note) {
+				assert.Equal(t, "Buy at the bakery", *task.Note)
+			}
+		}
+	}
+}
+
+func TestUpdateTaskwarriorTasks_taskFailedToConvert_isErrorAndNotRemoved(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	listID := "id-groceries"
+	client := newFakeClient()
+	client.addTask(listID, "task-1", "Milk", time.Now())
+	taskStore := taskwarrior.NewMemoryStore()
+	_, err = importOpenTasks(client, taskStore, &listID, nil, 1)
+	assert.NoError(t, err)
+
+	client.failedTaskIDs = []string{"task-1"}
+	stat, err := updateTaskwarriorTasks(client, taskStore, store, &listID, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), stat.taskCountError)
+	assert.Equal(t, int32(0), stat.taskCountRemoved)
+	_, ok := store.GetDeltaLink(listID)
+	assert.False(t, ok, "The delta link is stored although a task failed.")
+}
+
+func TestImportOpenTasks_taskFailedToConvert_isError(t *testing.T) {
+	listID := "id-groceries"
+	client := newFakeClient()
+	client.addTask(listID, "task-1", "Milk", time.Now())
+	client.addTask(listID, "task-2", "Bread", time.Now())
+	client.failedTaskIDs = []string{"task-1"}
+
+	stat, err := importOpenTasks(client, taskwarrior.NewMemoryStore(), &listID, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stat.taskCountFetched)
+	assert.Equal(t, int32(1), stat.taskCountCreated)
+	assert.Equal(t, int32(1), stat.taskCountError)
+}
+
+func TestUpdateTaskwarriorTasks_modificationDateMissingInToDo_isError(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	listID := "id-groceries"
+	client := newFakeClient()
+	milk := client.addTask(listID, "task-1", "Milk", time.Now())
+	taskStore := taskwarrior.NewMemoryStore()
+	_, err = importOpenTasks(client, taskStore, &listID, nil, 1)
+	assert.NoError(t, err)
+
+	oatMilk := "Oat milk"
+	milk.Title = &oatMilk
+	milk.ModifiedAt = nil
+
+	stat, err := updateTaskwarriorTasks(client, taskStore, store, &listID, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), stat.taskCountError)
+	assert.Equal(t, int32(0), stat.taskCountUpdated)
+	_, ok := store.GetDeltaLink(listID)
+	assert.False(t, ok, "The delta link is stored although a task failed.")
+}
+
+// newFakeToDoHandler returns a handler whose only account queries the fake To-Do server.
+func newFakeToDoHandler(t *testing.T, fake *test.FakeToDoServer) *Handler {
+	graphConfig := mstodo.DefaultConfig()
+	graphConfig.BaseURL = fake.URL
+	mstodo.Configure(graphConfig)
+	t.Cleanup(func() { mstodo.Configure(mstodo.DefaultConfig()) })
+
+	client, err := mstodo.NewGraphClient(&authentication.AnonymousAuthenticationProvider{})
+	assert.NoError(t, err)
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+
+	return &Handler{
+		accounts: newAccounts(map[string]mstodo.ClientFacade{
+			mstodo.DefaultAccount: client,
+		}),
+		taskStore:   taskwarrior.NewMemoryStore(),
+		store:       store,
+		workerCount: DefaultWorkerCount,
+	}
+}
+
+func TestOnTasksPull_fakeToDoServer_tasksImportedAndRemoved(t *testing.T) {
+	fake := test.NewFakeToDoServer(t)
+	listID := fake.AddList("Groceries")
+	milkID := fake.AddTask(listID, test.FakeTask{"title": "Milk"})
+	fake.AddTask(listID, test.FakeTask{"title": "Bread"})
+	fake.AddTask(listID, test.FakeTask{"title": "Eggs", "status": "completed"})
+	handler := newFakeToDoHandler(t, fake)
+
+	res := &Response{}
+	err := handler.OnTasksPull(Request{ListID: "Groceries"}, res)
+
+	assert.NoError(t, err)
+	assert.Contains(t, res.Message, "[Import] Open Tasks fetched from MS To-Do: 2")
+	assert.Contains(t, res.Message, "[Import] New Tasks created in Taskwarrior: 2")
+	tasks, err := handler.taskStore.ReadTasksAll()
+	if assert.NoError(t, err) {
+		assert.Len(t, *tasks, 2)
+	}
+
+	fake.DeleteTask(listID, milkID)
+	res = &Response{}
+	err = handler.OnTasksPull(Request{ListID: "Groceries"}, res)
+
+	assert.NoError(t, err)
+	assert.Contains(t, res.Message, "[Update] Tasks deleted in MS To-Do: 1")
+	assert.Contains(t, res.Message, "[Import] New Tasks created in Taskwarrior: 0")
+}
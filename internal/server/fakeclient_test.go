@@ -0,0 +1,205 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+)
+
+// fakeClient is a MS To-Do client with in-memory lists and tasks. It is the only fake
+// of mstodo.ClientFacade in the server tests, the Graph API itself is faked by
+// test.FakeToDoServer.
+type fakeClient struct {
+	lists          []models.TaskList
+	readListsCount int
+	// Tasks of all lists, key is the task ID.
+	tasks map[string]*models.Task
+	// Task IDs that are returned as removed by ReadTasksDelta.
+	removedTaskIDs []string
+	// Task IDs that are returned as failed to convert by ReadOpenTasks and
+	// ReadTasksDelta.
+	failedTaskIDs []string
+	// Tasks passed to UpdateTask.
+	updatedTasks []models.Task
+	// Delta links passed to ReadTasksDelta, an empty string if none was passed.
+	deltaLinks []string
+	// Delta link that is rejected as expired by ReadTasksDelta.
+	expiredDeltaLink string
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		lists: []models.TaskList{
+			{ID: "id-tasks", DisplayName: "Tasks", WellknownListName: "defaultList"},
+			{ID: "id-flagged", DisplayName: "Flagged email", WellknownListName: "flaggedEmails"},
+			{ID: "id-groceries", DisplayName: "Groceries", WellknownListName: "none"},
+			{ID: "id-work-1", DisplayName: "Work", WellknownListName: "none"},
+			{ID: "id-work-2", DisplayName: "Work", WellknownListName: "none"},
+		},
+		tasks: map[string]*models.Task{},
+	}
+}
+
+// addTask adds an open task to a list and returns it such that it can be changed.
+func (client *fakeClient) addTask(
+	listID string,
+	taskID string,
+	title string,
+	modifiedAt time.Time,
+) *models.Task {
+	completedAt := ""
+	dueAt := ""
+	importance := models.TODO_IMPORTANCE_NORMAL
+	note := ""
+	client.tasks[taskID] = &models.Task{
+		ToDoListID:  &listID,
+		ToDoTaskID:  &taskID,
+		Title:       &title,
+		CompletedAt: &completedAt,
+		DueAt:       &dueAt,
+		Importance:  &importance,
+		Note:        &note,
+		Categories:  []string{},
+		Status:      models.TW_TASKSTATUS_PENDING,
+		ModifiedAt:  &modifiedAt,
+	}
+	return client.tasks[taskID]
+}
+
+// listTasks returns the tasks of a list that have not failed to convert.
+func (client *fakeClient) listTasks(listID *string) []models.Task {
+	tasks := []models.Task{}
+	for _, task := range client.tasks {
+		if *task.ToDoListID == *listID && !client.isFailed(task) {
+			tasks = append(tasks, *task)
+		}
+	}
+	return tasks
+}
+
+// isFailed returns 'true' if the task is returned as failed to convert.
+func (client *fakeClient) isFailed(task *models.Task) bool {
+	for _, taskID := range client.failedTaskIDs {
+		if taskID == *task.ToDoTaskID {
+			return true
+		}
+	}
+	return false
+}
+
+// taskErrs returns the errors of the tasks that failed to convert.
+func (client *fakeClient) taskErrs() map[string]error {
+	taskErrs := map[string]error{}
+	for _, taskID := range client.failedTaskIDs {
+		taskErrs[taskID] = fmt.Errorf("[convTask] Task '%s' failed to convert.", taskID)
+	}
+	return taskErrs
+}
+
+func (client *fakeClient) ReadOpenTasks(
+	listID *string,
+) (*[]models.Task, map[string]error, int, error) {
+	tasks := []models.Task{}
+	for _, task := range client.listTasks(listID) {
+		if task.Status != models.TW_TASKSTATUS_COMPLETED {
+			tasks = append(tasks, task)
+		}
+	}
+	return &tasks, client.taskErrs(), 1, nil
+}
+
+func (client *fakeClient) ReadTaskByID(
+	listID *string,
+	taskID *string,
+) (*models.Task, error) {
+	task, ok := client.tasks[*taskID]
+	if !ok || *task.ToDoListID != *listID {
+		return nil, fmt.Errorf("[ReadTaskByID] Task '%s' does not exist.", *taskID)
+	}
+	taskCopy := *task
+	return &taskCopy, nil
+}
+
+func (client *fakeClient) ReadTasksByIDs(
+	listID *string,
+	taskIDs []string,
+) (map[string]*models.Task, map[string]error, error) {
+	tasks := map[string]*models.Task{}
+	taskErrs := map[string]error{}
+	for _, taskID := range taskIDs {
+		task, err := client.ReadTaskByID(listID, &taskID)
+		if err != nil {
+			taskErrs[taskID] = err
+			continue
+		}
+		tasks[taskID] = task
+	}
+	return tasks, taskErrs, nil
+}
+
+func (client *fakeClient) CreateTask(
+	listID *string,
+	task *models.Task,
+) (*models.Task, error) {
+	taskID := fmt.Sprintf("task-%d", len(client.tasks)+1)
+	createdTask := *task
+	createdTask.ToDoListID = listID
+	createdTask.ToDoTaskID = &taskID
+	modifiedAt := time.Now().UTC()
+	createdTask.ModifiedAt = &modifiedAt
+	client.tasks[taskID] = &createdTask
+	taskCopy := createdTask
+	return &taskCopy, nil
+}
+
+func (client *fakeClient) UpdateTask(task *models.Task) error {
+	client.updatedTasks = append(client.updatedTasks, *task)
+	updatedTask := *task
+	// A note that is 'nil' leaves the body unchanged.
+	if updatedTask.Note == nil {
+		updatedTask.Note = client.tasks[*task.ToDoTaskID].Note
+	}
+	modifiedAt := time.Now().UTC()
+	updatedTask.ModifiedAt = &modifiedAt
+	client.tasks[*task.ToDoTaskID] = &updatedTask
+	return nil
+}
+
+func (client *fakeClient) CreateList(displayName *string) (*models.TaskList, error) {
+	list := models.TaskList{ID: "id-" + *displayName, DisplayName: *displayName}
+	client.lists = append(client.lists, list)
+	return &list, nil
+}
+
+func (client *fakeClient) ReadLists() (*[]models.TaskList, error) {
+	client.readListsCount++
+	lists := append([]models.TaskList{}, client.lists...)
+	return &lists, nil
+}
+
+// ReadTasksDelta returns all tasks of the list, as a delta query without delta link.
+func (client *fakeClient) ReadTasksDelta(
+	listID *string,
+	deltaLink *string,
+) (*mstodo.TasksDelta, error) {
+	passedDeltaLink := ""
+	if deltaLink != nil {
+		passedDeltaLink = *deltaLink
+	}
+	client.deltaLinks = append(client.deltaLinks, passedDeltaLink)
+	if passedDeltaLink != "" && passedDeltaLink == client.expiredDeltaLink {
+		return nil, fmt.Errorf("[ReadTasksDelta] %w", mstodo.ErrDeltaTokenExpired)
+	}
+	return &mstodo.TasksDelta{
+		Tasks:          client.listTasks(listID),
+		RemovedTaskIDs: client.removedTaskIDs,
+		TaskErrs:       client.taskErrs(),
+		DeltaLink:      "delta-" + *listID,
+	}, nil
+}
+
+func (client *fakeClient) ReadThrottleStatistics() mstodo.ThrottleStatistics {
+	return mstodo.ThrottleStatistics{}
+}
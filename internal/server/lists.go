@@ -0,0 +1,111 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+)
+
+// errListNotFound is returned if no MS To-Do list has the requested ID or name.
+var errListNotFound = errors.New("no such list")
+
+// listResolver resolves a MS To-Do list given by its ID, display name or well-known list
+// name into its ID. The lists are read once and cached.
+type listResolver struct {
+	mutex sync.Mutex
+	lists *[]models.TaskList
+	// Key is the list ID, display name or well-known list name as requested.
+	resolved map[string]string
+}
+
+func newListResolver() *listResolver {
+	return &listResolver{resolved: map[string]string{}}
+}
+
+// resolve returns the ID of the MS To-Do list given by its ID, display name or
+// well-known list name 'defaultList' or 'flaggedEmails'. If the list is not found in the
+// cached lists, the lists are read again as the list may have been created meanwhile.
+func (resolver *listResolver) resolve(
+	client mstodo.ClientFacade,
+	list string,
+) (string, error) {
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	if list == "" {
+		return "", errors.New("[resolveList] No MS To-Do list given.")
+	}
+	if listID, ok := resolver.resolved[list]; ok {
+		return listID, nil
+	}
+
+	if resolver.lists != nil {
+		listID, err := findList(resolver.lists, list)
+		if err == nil {
+			resolver.resolved[list] = listID
+			return listID, nil
+		}
+	}
+
+	lists, err := client.ReadLists()
+	if err != nil {
+		return "", err
+	}
+	resolver.lists = lists
+
+	listID, err := findList(resolver.lists, list)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("[resolveList] List '%s' resolved to ID '%s'.\n", list, listID)
+	resolver.resolved[list] = listID
+
+	return listID, nil
+}
+
+// findList returns the ID of the list with the given ID, well-known list name or display
+// name, in this order.
+func findList(lists *[]models.TaskList, list string) (string, error) {
+	for _, taskList := range *lists {
+		if taskList.ID == list {
+			return taskList.ID, nil
+		}
+	}
+
+	if list == models.TODO_WELLKNOWNLIST_DEFAULTLIST ||
+		list == models.TODO_WELLKNOWNLIST_FLAGGEDEMAILS {
+		for _, taskList := range *lists {
+			if taskList.WellknownListName == list {
+				return taskList.ID, nil
+			}
+		}
+	}
+
+	var matchingIDs []string
+	for _, taskList := range *lists {
+		if taskList.DisplayName == list {
+			matchingIDs = append(matchingIDs, taskList.ID)
+		}
+	}
+	switch len(matchingIDs) {
+	case 0:
+		return "", fmt.Errorf(
+			"[resolveList] Failed to resolve list '%s': %w",
+			list,
+			errListNotFound,
+		)
+	case 1:
+		return matchingIDs[0], nil
+	}
+
+	return "", errors.New(fmt.Sprintf(
+		"[resolveList] Failed to resolve list '%s': ambiguous list name, pass one of "+
+			"the list IDs instead: %s",
+		list,
+		strings.Join(matchingIDs, ", "),
+	))
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveList_idNameAndAlias(t *testing.T) {
+	client := newFakeClient()
+	resolver := newListResolver()
+
+	for list, expectedID := range map[string]string{
+		"id-groceries":  "id-groceries",
+		"Groceries":     "id-groceries",
+		"defaultList":   "id-tasks",
+		"flaggedEmails": "id-flagged",
+		"id-work-1":     "id-work-1",
+	} {
+		listID, err := resolver.resolve(client, list)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedID, listID)
+	}
+	assert.Equal(t, 1, client.readListsCount, "Lists are not cached.")
+}
+
+func TestResolveList_ambiguousName_isError(t *testing.T) {
+	_, err := newListResolver().resolve(newFakeClient(), "Work")
+
+	assert.ErrorContains(t, err, "ambiguous list name")
+}
+
+func TestResolveList_unknownList_isErrorAfterRefresh(t *testing.T) {
+	client := newFakeClient()
+	resolver := newListResolver()
+	_, err := resolver.resolve(client, "Groceries")
+	assert.NoError(t, err)
+
+	_, err = resolver.resolve(client, "Holidays")
+
+	assert.ErrorContains(t, err, "no such list")
+	assert.Equal(t, 2, client.readListsCount)
+}
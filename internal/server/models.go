@@ -1,10 +1,54 @@
 package server
 
+import (
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/simachri/taskwarrior-ms-todo/internal/taskwarrior"
+)
+
 type Request struct {
+	// Account of the credentials.yaml. If empty, the only account or the account
+	// 'default' is used.
+	Account string
+	// MS To-Do list ID, display name or well-known list name 'defaultList' or
+	// 'flaggedEmails'.
 	ListID string
+	// Taskwarrior filter that selects the tasks to push to MS To-Do.
+	Filter string
+	// Lists of the config 'sync.lists'. If no ListID is given, all of them are pulled.
+	Lists []ListMapping
+	// Patterns of the Taskwarrior projects, for example 'work.*', whose tasks are pushed
+	// to a MS To-Do list per project if no ListID is given.
+	Projects []string
+}
+
+// ListMapping maps a MS To-Do list to the Taskwarrior attributes of its tasks.
+type ListMapping struct {
+	// MS To-Do list ID, display name or well-known list name 'defaultList' or
+	// 'flaggedEmails'.
+	List string
+	// Account of the credentials.yaml the list belongs to. If empty, the only account or
+	// the account 'default' is used.
+	Account string
+	// 'project' of the Taskwarrior tasks created for the list.
+	Project string
+	// Tags of the Taskwarrior tasks created for the list.
+	Tags []string
+	// 'priority' of the Taskwarrior tasks whose importance has no priority.
+	Priority string
+}
+
+// defaults returns the Taskwarrior attributes of the tasks of the list.
+func (mapping *ListMapping) defaults() *taskwarrior.ListDefaults {
+	return &taskwarrior.ListDefaults{
+		Project:  mapping.Project,
+		Tags:     mapping.Tags,
+		Priority: mapping.Priority,
+	}
 }
 
 type Response struct {
 	Message string
+	// MS To-Do lists read by the command 'lists'.
+	Lists []models.TaskList
 }
 
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+	"github.com/simachri/taskwarrior-ms-todo/internal/state"
+	"github.com/simachri/taskwarrior-ms-todo/internal/taskwarrior"
+)
+
+// matchesProjectPatterns returns 'true' if the Taskwarrior project matches one of the
+// patterns, for example 'work.*'.
+func matchesProjectPatterns(project string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, project); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// projectListID returns the ID of the MS To-Do list of a Taskwarrior project in the
+// given account. If the tasks of the project are linked to a list of the account that
+// has been created for another project, the project has been renamed in Taskwarrior and
+// the mapping is updated. Otherwise, the list of the account named like the project is
+// used and only created if it does not exist yet.
+func projectListID(
+	acc *account,
+	taskStore taskwarrior.TaskStore,
+	store *state.Store,
+	project string,
+) (listID string, created bool, err error) {
+	for listID, listProject := range store.GetProjectLists() {
+		if listProject == project && isListOfAccount(store, listID, acc) {
+			return listID, false, nil
+		}
+	}
+
+	linkedListIDs, err := taskStore.ReadProjectListIDs(project)
+	if err != nil {
+		return "", false, err
+	}
+	for _, linkedListID := range linkedListIDs {
+		previousProject, ok := store.GetListProject(linkedListID)
+		if ok && isListOfAccount(store, linkedListID, acc) {
+			fmt.Printf(
+				"[projectListID] Project '%s' has been renamed to '%s'.\n",
+				previousProject,
+				project,
+			)
+			store.SetListProject(linkedListID, project)
+			return linkedListID, false, nil
+		}
+	}
+
+	// The list may exist without mapping, for example if it has been created in MS
+	// To-Do or the sync state has been lost.
+	listID, err = acc.lists.resolve(acc.client, project)
+	if err != nil && !errors.Is(err, errListNotFound) {
+		return "", false, err
+	}
+	if _, isMapped := store.GetListProject(listID); err == nil && !isMapped {
+		fmt.Printf(
+			"[projectListID] Existing list '%s' is used for project '%s'.\n",
+			listID,
+			project,
+		)
+		store.SetListProject(listID, project)
+		store.SetListAccount(listID, acc.name)
+		return listID, false, nil
+	}
+
+	list, err := acc.client.CreateList(&project)
+	if err != nil {
+		return "", false, err
+	}
+	store.SetListProject(list.ID, project)
+	store.SetListAccount(list.ID, acc.name)
+
+	return list.ID, true, nil
+}
+
+// isListOfAccount returns 'true' if a list created for a project belongs to the given
+// account. Lists created before accounts were supported belong to the account
+// 'default'.
+func isListOfAccount(store *state.Store, listID string, acc *account) bool {
+	accountName, ok := store.GetListAccount(listID)
+	if !ok {
+		accountName = mstodo.DefaultAccount
+	}
+	return accountName == acc.name
+}
+
+// pushProjects pushes the tasks of each Taskwarrior project that matches one of the
+// patterns to the MS To-Do list of the project and returns the statistics.
+func pushProjects(
+	acc *account,
+	taskStore taskwarrior.TaskStore,
+	store *state.Store,
+	patterns []string,
+	filter *string,
+) (string, error) {
+	projects, err := taskStore.ReadProjects()
+	if err != nil {
+		return "", err
+	}
+
+	message := ""
+	for _, project := range projects {
+		if !matchesProjectPatterns(project, patterns) {
+			continue
+		}
+		message += fmt.Sprintf("\n  [Project] '%s':\n", project)
+
+		listID, created, err := projectListID(acc, taskStore, store, project)
+		if err != nil {
+			message += fmt.Sprintf("    Error: %v", err)
+			continue
+		}
+		if created {
+			message += fmt.Sprintf("    [Push] MS To-Do list created: %s\n", listID)
+		}
+
+		projectFilter := taskwarrior.ProjectFilter(project)
+		if filter != nil && *filter != "" {
+			projectFilter = fmt.Sprintf("%s ( %s )", projectFilter, *filter)
+		}
+		pushStat, err := pushTasks(acc.client, taskStore, &listID, &projectFilter)
+		if err != nil {
+			message += fmt.Sprintf("    Error: %v", err)
+			continue
+		}
+		message += fmt.Sprintf(
+			"    [Push] Taskwarrior tasks not yet in MS To-Do: %v\n"+
+				"    [Push] New Tasks created in MS To-Do: %v\n"+
+				"    [Push] Errors: %v",
+			pushStat.taskCountFetched,
+			pushStat.taskCountCreated,
+			pushStat.taskCountError,
+		)
+	}
+
+	err = store.Save()
+	if err != nil {
+		return message, err
+	}
+
+	return message, nil
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/mstodo"
+	"github.com/simachri/taskwarrior-ms-todo/internal/state"
+	"github.com/simachri/taskwarrior-ms-todo/internal/taskwarrior"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesProjectPatterns(t *testing.T) {
+	patterns := []string{"work.*", "home"}
+
+	assert.True(t, matchesProjectPatterns("work.clientA", patterns))
+	assert.True(t, matchesProjectPatterns("work.clientA.meetings", patterns))
+	assert.True(t, matchesProjectPatterns("home", patterns))
+	assert.False(t, matchesProjectPatterns("work", patterns))
+	assert.False(t, matchesProjectPatterns("home.garden", patterns))
+}
+
+func newProjectsTestStore(t *testing.T) *state.Store {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestProjectListID_listOfOtherAccount_isNotUsed(t *testing.T) {
+	store := newProjectsTestStore(t)
+	store.SetListProject("id-errands-default", "Errands")
+	store.SetListAccount("id-errands-default", mstodo.DefaultAccount)
+	client := newFakeClient()
+	acc := newAccounts(map[string]mstodo.ClientFacade{"work": client})["work"]
+
+	listID, created, err := projectListID(acc, taskwarrior.NewMemoryStore(), store, "Errands")
+
+	if assert.NoError(t, err) {
+		assert.True(t, created)
+		assert.Equal(t, "id-Errands", listID)
+		accountName, _ := store.GetListAccount(listID)
+		assert.Equal(t, "work", accountName)
+	}
+}
+
+func TestProjectListID_listWithoutAccount_isUsedByDefaultAccount(t *testing.T) {
+	store := newProjectsTestStore(t)
+	store.SetListProject("id-errands", "Errands")
+	acc := newAccounts(
+		map[string]mstodo.ClientFacade{mstodo.DefaultAccount: newFakeClient()},
+	)[mstodo.DefaultAccount]
+
+	listID, created, err := projectListID(acc, taskwarrior.NewMemoryStore(), store, "Errands")
+
+	if assert.NoError(t, err) {
+		assert.False(t, created)
+		assert.Equal(t, "id-errands", listID)
+	}
+}
+
+func TestProjectListID_existingListNamedLikeProject_isUsed(t *testing.T) {
+	store := newProjectsTestStore(t)
+	client := newFakeClient()
+	acc := newAccounts(map[string]mstodo.ClientFacade{"work": client})["work"]
+
+	listID, created, err := projectListID(
+		acc,
+		taskwarrior.NewMemoryStore(),
+		store,
+		"Groceries",
+	)
+
+	if assert.NoError(t, err) {
+		assert.False(t, created)
+		assert.Equal(t, "id-groceries", listID)
+		assert.Len(t, client.lists, 5)
+		project, _ := store.GetListProject(listID)
+		assert.Equal(t, "Groceries", project)
+		accountName, _ := store.GetListAccount(listID)
+		assert.Equal(t, "work", accountName)
+	}
+}
+
+func TestProjectListID_ambiguousListName_isError(t *testing.T) {
+	acc := newAccounts(
+		map[string]mstodo.ClientFacade{mstodo.DefaultAccount: newFakeClient()},
+	)[mstodo.DefaultAccount]
+
+	_, _, err := projectListID(
+		acc,
+		taskwarrior.NewMemoryStore(),
+		newProjectsTestStore(t),
+		"Work",
+	)
+
+	assert.ErrorContains(t, err, "ambiguous")
+}
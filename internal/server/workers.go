@@ -0,0 +1,37 @@
+package server
+
+import "sync"
+
+// DefaultWorkerCount is the number of tasks processed concurrently if the config
+// 'server.workers' is not set.
+const DefaultWorkerCount = 4
+
+// runWorkers calls 'process' for each index from 0 to 'count' - 1 by a pool of
+// 'workerCount' goroutines and returns when all indexes are processed. 'process' has
+// to be safe for concurrent use.
+func runWorkers(workerCount int, count int, process func(index int)) {
+	if workerCount < 1 {
+		workerCount = 1
+	}
+	if workerCount > count {
+		workerCount = count
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				process(index)
+			}
+		}()
+	}
+
+	for index := 0; index < count; index++ {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+}
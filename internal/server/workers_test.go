@@ -0,0 +1,42 @@
+package server
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWorkers_allProcessedWithinWorkerCount(t *testing.T) {
+	var processed [10]int32
+	var running, maxRunning int32
+
+	runWorkers(3, len(processed), func(index int) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&processed[index], 1)
+		atomic.AddInt32(&running, -1)
+	})
+
+	for index := range processed {
+		assert.Equal(t, int32(1), processed[index], "Index %v", index)
+	}
+	assert.LessOrEqual(t, maxRunning, int32(3))
+}
+
+func TestRunWorkers_noWorkers_isSequential(t *testing.T) {
+	var order []int
+
+	runWorkers(0, 3, func(index int) {
+		order = append(order, index)
+	})
+
+	assert.Equal(t, []int{0, 1, 2}, order)
+}
@@ -0,0 +1,241 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TaskState is the state of a task at the point in time it was last synced.
+type TaskState struct {
+	// 'modified' of the Taskwarrior task.
+	TaskwarriorModifiedAt time.Time
+	// 'lastModifiedDateTime' of the MS To-Do task.
+	ToDoModifiedAt time.Time
+}
+
+// Store persists the sync state between runs of the server as JSON file.
+type Store struct {
+	path  string
+	mutex sync.Mutex
+	// Key is the MS To-Do Task ID.
+	Tasks map[string]TaskState
+	// Taskwarrior project of the MS To-Do lists created for projects. Key is the MS To-Do
+	// List ID such that the mapping survives a rename of the list.
+	ProjectLists map[string]string
+	// Account of the credentials.yaml of the MS To-Do lists created for projects. Key is
+	// the MS To-Do List ID.
+	ListAccounts map[string]string
+	// Delta link of the last delta query of the tasks of a MS To-Do list. Key is the MS
+	// To-Do List ID.
+	DeltaLinks map[string]string
+	// MS To-Do Task IDs of the tasks that have been deleted in MS To-Do such that their
+	// linked Taskwarrior tasks are not read by ID again.
+	RemovedTasks map[string]bool
+}
+
+// Load reads the sync state from the given file. If the file does not exist, an empty
+// state is returned.
+func Load(path string) (*Store, error) {
+	store := &Store{
+		path:         path,
+		Tasks:        map[string]TaskState{},
+		ProjectLists: map[string]string{},
+		ListAccounts: map[string]string{},
+		DeltaLinks:   map[string]string{},
+		RemovedTasks: map[string]bool{},
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("[Load] Failed to read sync state file '%s': %w", path, err)
+	}
+
+	err = json.Unmarshal(content, store)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[Load] Failed to unmarshall sync state file '%s': %w",
+			path,
+			err,
+		)
+	}
+	if store.Tasks == nil {
+		store.Tasks = map[string]TaskState{}
+	}
+	if store.ProjectLists == nil {
+		store.ProjectLists = map[string]string{}
+	}
+	if store.ListAccounts == nil {
+		store.ListAccounts = map[string]string{}
+	}
+	if store.DeltaLinks == nil {
+		store.DeltaLinks = map[string]string{}
+	}
+	if store.RemovedTasks == nil {
+		store.RemovedTasks = map[string]bool{}
+	}
+
+	return store, nil
+}
+
+// GetTaskState returns the state of a task at its last sync. 'false' is returned if the
+// task has not been synced yet.
+func (store *Store) GetTaskState(toDoTaskID string) (TaskState, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	taskState, ok := store.Tasks[toDoTaskID]
+	return taskState, ok
+}
+
+// SetTaskState records the state of a synced task. Call Save() to persist it.
+func (store *Store) SetTaskState(toDoTaskID string, taskState TaskState) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.Tasks[toDoTaskID] = taskState
+}
+
+// IsTaskRemoved returns 'true' if the task has been deleted in MS To-Do.
+func (store *Store) IsTaskRemoved(toDoTaskID string) bool {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.RemovedTasks[toDoTaskID]
+}
+
+// SetTaskRemoved records that a task has been deleted in MS To-Do and drops its state.
+// Call Save() to persist it.
+func (store *Store) SetTaskRemoved(toDoTaskID string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.RemovedTasks[toDoTaskID] = true
+	delete(store.Tasks, toDoTaskID)
+}
+
+// GetProjectListID returns the ID of the MS To-Do list created for a Taskwarrior project.
+// 'false' is returned if no list has been created for the project.
+func (store *Store) GetProjectListID(project string) (string, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for listID, listProject := range store.ProjectLists {
+		if listProject == project {
+			return listID, true
+		}
+	}
+	return "", false
+}
+
+// GetListProject returns the Taskwarrior project of a MS To-Do list created for a
+// project. 'false' is returned if the list has not been created for a project.
+func (store *Store) GetListProject(listID string) (string, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	project, ok := store.ProjectLists[listID]
+	return project, ok
+}
+
+// GetProjectLists returns the Taskwarrior project of each MS To-Do list created for a
+// project. Key is the MS To-Do List ID.
+func (store *Store) GetProjectLists() map[string]string {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	projectLists := map[string]string{}
+	for listID, project := range store.ProjectLists {
+		projectLists[listID] = project
+	}
+	return projectLists
+}
+
+// SetListProject records the Taskwarrior project of a MS To-Do list. Call Save() to
+// persist it.
+func (store *Store) SetListProject(listID string, project string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.ProjectLists[listID] = project
+}
+
+// GetListAccount returns the account of a MS To-Do list created for a project. 'false'
+// is returned if no account has been recorded for the list.
+func (store *Store) GetListAccount(listID string) (string, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	account, ok := store.ListAccounts[listID]
+	return account, ok
+}
+
+// SetListAccount records the account of a MS To-Do list created for a project. Call
+// Save() to persist it.
+func (store *Store) SetListAccount(listID string, account string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.ListAccounts[listID] = account
+}
+
+// GetDeltaLink returns the delta link of the last delta query of the tasks of a MS
+// To-Do list. 'false' is returned if the tasks of the list have not been queried yet.
+func (store *Store) GetDeltaLink(listID string) (string, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	deltaLink, ok := store.DeltaLinks[listID]
+	return deltaLink, ok
+}
+
+// SetDeltaLink records the delta link of a delta query of the tasks of a MS To-Do list.
+// An empty delta link removes the recorded one. Call Save() to persist it.
+func (store *Store) SetDeltaLink(listID string, deltaLink string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if deltaLink == "" {
+		delete(store.DeltaLinks, listID)
+		return
+	}
+	store.DeltaLinks[listID] = deltaLink
+}
+
+// Save writes the sync state to its file.
+func (store *Store) Save() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	content, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("[Save] Failed to marshall sync state: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(store.path), 0700)
+	if err != nil {
+		return fmt.Errorf("[Save] Failed to create directory of '%s': %w", store.path, err)
+	}
+
+	// Write to a temporary file first such that the state is not corrupted if the
+	// server is stopped while writing.
+	tmpPath := store.path + ".tmp"
+	err = os.WriteFile(tmpPath, content, 0600)
+	if err != nil {
+		return fmt.Errorf("[Save] Failed to write sync state file '%s': %w", tmpPath, err)
+	}
+
+	err = os.Rename(tmpPath, store.path)
+	if err != nil {
+		return fmt.Errorf("[Save] Failed to write sync state file '%s': %w", store.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_fileMissing_isEmpty(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "state.json"))
+
+	assert.NoError(t, err)
+	_, ok := store.GetTaskState("foo")
+	assert.False(t, ok)
+}
+
+func TestSave_loadAgain_hasTaskState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "twtodo", "state.json")
+	store, err := Load(path)
+	assert.NoError(t, err)
+
+	taskState := TaskState{
+		TaskwarriorModifiedAt: time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC),
+		ToDoModifiedAt:        time.Date(2022, 8, 2, 12, 0, 0, 0, time.UTC),
+	}
+	store.SetTaskState("foo", taskState)
+	err = store.Save()
+	assert.NoError(t, err)
+
+	store, err = Load(path)
+	assert.NoError(t, err)
+	loadedTaskState, ok := store.GetTaskState("foo")
+	assert.True(t, ok)
+	assert.True(t, taskState.TaskwarriorModifiedAt.Equal(loadedTaskState.TaskwarriorModifiedAt))
+	assert.True(t, taskState.ToDoModifiedAt.Equal(loadedTaskState.ToDoModifiedAt))
+}
+
+func TestSetListProject_projectRenamed_listIsKept(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Load(path)
+	assert.NoError(t, err)
+
+	store.SetListProject("list-1", "work.a")
+	store.SetListProject("list-1", "work.b")
+	err = store.Save()
+	assert.NoError(t, err)
+
+	store, err = Load(path)
+	assert.NoError(t, err)
+	_, ok := store.GetProjectListID("work.a")
+	assert.False(t, ok)
+	listID, ok := store.GetProjectListID("work.b")
+	assert.True(t, ok)
+	assert.Equal(t, "list-1", listID)
+	project, ok := store.GetListProject("list-1")
+	assert.True(t, ok)
+	assert.Equal(t, "work.b", project)
+}
+
+func TestSetDeltaLink_emptyDeltaLink_isRemoved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Load(path)
+	assert.NoError(t, err)
+
+	store.SetDeltaLink("list-1", "https://graph.microsoft.com/delta?$deltatoken=1")
+	store.SetDeltaLink("list-2", "https://graph.microsoft.com/delta?$deltatoken=2")
+	store.SetDeltaLink("list-2", "")
+	err = store.Save()
+	assert.NoError(t, err)
+
+	store, err = Load(path)
+	assert.NoError(t, err)
+	deltaLink, ok := store.GetDeltaLink("list-1")
+	assert.True(t, ok)
+	assert.Equal(t, "https://graph.microsoft.com/delta?$deltatoken=1", deltaLink)
+	_, ok = store.GetDeltaLink("list-2")
+	assert.False(t, ok)
+}
+
+func TestSetListAccount_saved_isLoaded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Load(path)
+	assert.NoError(t, err)
+
+	store.SetListAccount("list-1", "work")
+	err = store.Save()
+	assert.NoError(t, err)
+
+	store, err = Load(path)
+	assert.NoError(t, err)
+	account, ok := store.GetListAccount("list-1")
+	assert.True(t, ok)
+	assert.Equal(t, "work", account)
+	_, ok = store.GetListAccount("list-2")
+	assert.False(t, ok)
+}
+
+func TestSetTaskRemoved_saved_isLoadedWithoutTaskState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Load(path)
+	assert.NoError(t, err)
+
+	store.SetTaskState("task-1", TaskState{})
+	store.SetTaskRemoved("task-1")
+	err = store.Save()
+	assert.NoError(t, err)
+
+	store, err = Load(path)
+	assert.NoError(t, err)
+	assert.True(t, store.IsTaskRemoved("task-1"))
+	assert.False(t, store.IsTaskRemoved("task-2"))
+	_, ok := store.GetTaskState("task-1")
+	assert.False(t, ok)
+}
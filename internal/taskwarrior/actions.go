@@ -3,18 +3,35 @@ package taskwarrior
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
 )
 
 type ImportResult int32
 
+// writeMutex serializes the actions that write Taskwarrior tasks such that concurrent
+// callers do not compete for the lock file of Taskwarrior. It also makes the check for
+// an existing task and the write atomic.
+var writeMutex sync.Mutex
+
 const (
 	TASK_CREATED ImportResult = iota
 	TASK_EXISTS_AND_SKIPPED
+	// The task is an occurrence of a recurring task and has been linked to a child task
+	// of the recurring Taskwarrior task.
+	TASK_LINKED_TO_RECURRENCE
+	// The recurrence of the task cannot be represented in Taskwarrior. The task has been
+	// created without recurrence.
+	TASK_CREATED_WITHOUT_RECURRENCE
 )
 
-func Import(task *models.Task) (ImportResult, error) {
+// Import creates a Taskwarrior task for a MS To-Do task with the given defaults of its
+// list. 'defaults' may be 'nil'.
+func Import(task *models.Task, defaults *ListDefaults) (ImportResult, error) {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
 	toDoListID := task.ToDoListID
 	toDoTaskID := task.ToDoTaskID
 
@@ -26,15 +43,61 @@ func Import(task *models.Task) (ImportResult, error) {
 		return TASK_EXISTS_AND_SKIPPED, nil
 	}
 
-	_, err = createTask(task.Title, toDoListID, toDoTaskID)
+	if task.Recurrence == nil {
+		_, err = createTask(task, defaults)
+		if err != nil {
+			return -1, err
+		}
+		return TASK_CREATED, nil
+	}
+
+	recur, until, err := models.ConvRecurrenceToTW(task.Recurrence, task.DueAt)
+	if err != nil {
+		fmt.Printf(
+			"[Import] Recurrence of task '%s' cannot be represented in Taskwarrior, the "+
+				"task is created without recurrence: %v\n",
+			*task.Title,
+			err,
+		)
+		_, err = createTask(task, defaults)
+		if err != nil {
+			return -1, err
+		}
+		return TASK_CREATED_WITHOUT_RECURRENCE, nil
+	}
+
+	linked, err := linkRecurringChild(task, defaults)
+	if err != nil {
+		return -1, err
+	}
+	if linked {
+		return TASK_LINKED_TO_RECURRENCE, nil
+	}
+
+	err = createRecurringTask(task, recur, until, defaults)
+	if err != nil {
+		return -1, err
+	}
+	linked, err = linkRecurringChild(task, defaults)
 	if err != nil {
 		return -1, err
 	}
+	if !linked {
+		return -1, errors.New(fmt.Sprintf(
+			"[Import] Taskwarrior has not created a child task for the recurring task '%s'.",
+			*task.Title,
+		))
+	}
 
 	return TASK_CREATED, nil
 }
 
-func Update(task *models.TaskwarriorTask) error {
+// Update transfers a MS To-Do task to its linked Taskwarrior task with the given defaults
+// of its list. 'defaults' may be 'nil'.
+func Update(task *models.TaskwarriorTask, defaults *ListDefaults) error {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
 	taskExists, err := taskExists(task.ToDoListID, task.ToDoTaskID)
 	if err != nil {
 		return err
@@ -47,5 +110,26 @@ func Update(task *models.TaskwarriorTask) error {
 		)
 	}
 
-	return update(task)
+	return update(task, defaults)
+}
+
+// Link stores the MS To-Do list and task ID in an existing Taskwarrior task that is
+// not yet linked to MS To-Do.
+func Link(task *models.TaskwarriorTask) error {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
+	taskExists, err := taskExists(task.ToDoListID, task.ToDoTaskID)
+	if err != nil {
+		return err
+	}
+	if taskExists {
+		return errors.New(
+			fmt.Sprintf("[Link] Failed - a Taskwarrior task already exists for\n"+
+				"MS To-Do List ID: %s\n"+
+				"MS To-Do Task ID: %s\n", *task.ToDoListID, *task.ToDoTaskID),
+		)
+	}
+
+	return link(task)
 }
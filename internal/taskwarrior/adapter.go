@@ -4,91 +4,310 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/simachri/taskwarrior-ms-todo/internal/models"
 )
 
+// Prefix of the annotation that holds the note of a MS To-Do task.
+const noteAnnotationPrefix = "[MS To-Do] "
+
 // taskExists returns 'true' if a Taskwarrior task for the given Microsoft To-Do List and
-// Task ID exists in the given task list, otherwise 'false'.
+// Task ID exists in the given task list, otherwise 'false'. Tasks of any status but
+// 'deleted' are considered, also waiting and completed tasks.
 func taskExists(toDoListID *string, toDoTaskID *string) (bool, error) {
-	cmd := exec.Command(
-		"bash",
-		"-c",
-		fmt.Sprintf(
-			"task %s:%s %s:%s",
-			models.UDANameTodoListID,
-			*toDoListID,
-			models.UDANameTodoTaskID,
-			*toDoTaskID,
-		),
+	// Unlike a report, for example the default report 'next', the export does not hide
+	// waiting and completed tasks.
+	tasksJSON, err := exportTasksJSON(
+		"status.not:deleted",
+		attrArg(models.UDANameTodoListID, *toDoListID),
+		attrArg(models.UDANameTodoTaskID, *toDoTaskID),
 	)
-	out, err := cmd.CombinedOutput()
 	if err != nil {
-		exitErr, ok := err.(*exec.ExitError)
-		if ok == true && exitErr.ExitCode() == 1 {
-			// Exit code is 1 if task does not exist.
-			return false, nil
-		}
-
-		fmt.Printf("[taskExists] Command:\n%s\n", cmd.Args)
-		fmt.Printf("[taskExists] Output:\n%s\n", out)
 		return false, fmt.Errorf(
 			"[taskExists] Failed to check task existence:\nTo-Do List ID: %v\n"+
 				"To-Do Task ID: %v\n"+
 				"Error: %w\n",
-			toDoListID,
-			toDoTaskID,
+			*toDoListID,
+			*toDoTaskID,
 			err,
 		)
 	}
 
-	return true, nil
+	return len(*tasksJSON) > 0, nil
 }
 
 // createTask creates a Taskwarrior task using the 'task' CLI.
 // The Microsoft To-Do task and list IDs are stored as user-defined attribute (UDA) in
 // the Taskwarrior task.
-func createTask(
-	title *string,
-	todoListID *string,
-	todoTaskID *string,
-) (taskUUID string, err error) {
-	// 'task add ...' returns a message 'Created task 42.'
-	cmd := exec.Command(
-		"bash",
-		"-c",
-		fmt.Sprintf(
-			"task add '%s' %s:'%s' %s:'%s'",
-			*title,
-			models.UDANameTodoListID,
-			*todoListID,
-			models.UDANameTodoTaskID,
-			*todoTaskID,
-		)+
-			// Extract the task ID
-			" | grep -oP '[0-9]+'"+
-			// Extract the task UUID.
-			" | xargs -I '{id}' task _get {id}.uuid",
-	)
+func createTask(task *models.Task, defaults *ListDefaults) (taskUUID string, err error) {
+	statusMods, err := statusModifications(task, false)
+	if err != nil {
+		return "", err
+	}
+	dueMod, err := dueModification(task)
+	if err != nil {
+		return "", err
+	}
 
-	uuid, err := cmd.Output()
+	args := []string{
+		"rc.verbose=new-uuid",
+		"add",
+		attrArg(models.UDANameTodoListID, *task.ToDoListID),
+		attrArg(models.UDANameTodoTaskID, *task.ToDoTaskID),
+	}
+	args = append(args, statusMods...)
+	args = append(args, dueMod, "priority:"+defaults.priority(task.Importance))
+	args = append(args, tagModifications(nil, task.Categories, defaults)...)
+	args = append(args, defaults.modifications()...)
+	// The title is not parsed by Taskwarrior, for example 'project:' or '+tag'.
+	args = append(args, "--", *task.Title)
+
+	out, err := taskCommand(args...).CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf(
-			"[createTask] Failed to create task and extract the UUID: %w\n",
+			"[createTask] Failed to create task: %w\nOutput of command: %s\n",
 			err,
+			out,
 		)
 	}
 
-	// The UUID has a trailing newline character that needs to be stripped.
-	return string(uuid[:len(uuid)-1]), nil
+	taskUUID, err = parseCreatedUUID(out)
+	if err != nil {
+		return "", err
+	}
+
+	if task.Note != nil && *task.Note != "" {
+		err = annotate(&taskUUID, task.Note)
+		if err != nil {
+			return taskUUID, err
+		}
+	}
+
+	err = updateChecklistItems(&taskUUID, task)
+	if err != nil {
+		return taskUUID, err
+	}
+
+	return taskUUID, nil
+}
+
+// parseNoteFromJSON returns the text of the annotation that holds the MS To-Do note of
+// a task. An empty string is returned if the task has no such annotation.
+func parseNoteFromJSON(taskJSON *map[string]interface{}) string {
+	annotations, ok := (*taskJSON)["annotations"].([]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, annotation := range annotations {
+		annotationJSON, ok := annotation.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		description, ok := annotationJSON["description"].(string)
+		if ok && strings.HasPrefix(description, noteAnnotationPrefix) {
+			return strings.TrimPrefix(description, noteAnnotationPrefix)
+		}
+	}
+
+	return ""
+}
+
+// annotate adds the MS To-Do note as annotation to a task.
+func annotate(taskUUID *string, note *string) error {
+	out, err := taskCommand(
+		*taskUUID,
+		"annotate",
+		"--",
+		noteAnnotationPrefix+*note,
+	).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"[annotate] Failed to annotate task '%s': %w\nOutput of command: %s\n",
+			*taskUUID,
+			err,
+			out,
+		)
+	}
+
+	return nil
+}
+
+// denotate removes the annotation that holds the MS To-Do note from a task.
+func denotate(taskUUID *string, note *string) error {
+	out, err := taskCommand(
+		*taskUUID,
+		"denotate",
+		"--",
+		noteAnnotationPrefix+*note,
+	).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"[denotate] Failed to denotate task '%s': %w\nOutput of command: %s\n",
+			*taskUUID,
+			err,
+			out,
+		)
+	}
+
+	return nil
+}
+
+// updateNote replaces the annotation that holds the MS To-Do note of a task instead of
+// adding another one.
+func updateNote(taskUUID *string, currentNote *string, note *string) error {
+	if note == nil || *note == *currentNote {
+		return nil
+	}
+
+	if *currentNote != "" {
+		err := denotate(taskUUID, currentNote)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *note != "" {
+		return annotate(taskUUID, note)
+	}
+
+	return nil
+}
+
+// tagModifications returns the Taskwarrior tag modifications that replace the tags of
+// the current categories by the tags of the given categories. Tags that are not synced
+// and default tags of the list are left untouched.
+func tagModifications(
+	currentCategories []string,
+	categories []string,
+	defaults *ListDefaults,
+) []string {
+	tags := map[string]bool{}
+	var mods []string
+	for _, category := range categories {
+		tag := convCategoryToTag(category)
+		if !isTagSynced(tag) || tags[tag] {
+			continue
+		}
+		tags[tag] = true
+		mods = append(mods, tagArg(tag, true))
+	}
+	for _, category := range currentCategories {
+		tag := convCategoryToTag(category)
+		if !tags[tag] && !defaults.isDefaultTag(tag) {
+			mods = append(mods, tagArg(tag, false))
+		}
+	}
+
+	return mods
+}
+
+// updateTags replaces the synced tags of a task by the tags of the given categories.
+func updateTags(
+	taskUUID *string,
+	currentCategories []string,
+	categories []string,
+	defaults *ListDefaults,
+) error {
+	if categories == nil {
+		return nil
+	}
+	tagMods := tagModifications(currentCategories, categories, defaults)
+	if len(tagMods) == 0 {
+		return nil
+	}
+
+	out, err := taskCommand(append([]string{*taskUUID, "modify"}, tagMods...)...).
+		CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"[updateTags] Failed to update tags of task '%s': %w\nOutput of command: %s\n",
+			*taskUUID,
+			err,
+			out,
+		)
+	}
+
+	return nil
+}
+
+// parseCategoriesFromJSON returns the MS To-Do categories of the synced tags of a task.
+func parseCategoriesFromJSON(taskJSON *map[string]interface{}) []string {
+	categories := []string{}
+	tags, ok := (*taskJSON)["tags"].([]interface{})
+	if !ok {
+		return categories
+	}
+
+	for _, tag := range tags {
+		tagStr, ok := tag.(string)
+		if ok && isTagSynced(tagStr) {
+			categories = append(categories, convTagToCategory(tagStr))
+		}
+	}
+
+	return categories
+}
+
+// statusModifications returns the Taskwarrior attributes that represent the status of
+// the given task. The 'start' date of a task that is already started is kept.
+func statusModifications(task *models.Task, isStarted bool) ([]string, error) {
+	switch task.Status {
+	case models.TW_TASKSTATUS_PENDING:
+		return []string{"status:pending", "start:", "wait:", "end:"}, nil
+
+	case models.TW_TASKSTATUS_STARTED:
+		if isStarted {
+			return []string{"status:pending", "wait:", "end:"}, nil
+		}
+		return []string{"status:pending", "start:now", "wait:", "end:"}, nil
+
+	case models.TW_TASKSTATUS_WAITING:
+		return []string{"status:pending", "start:", "wait:" + config.Wait, "end:"}, nil
+
+	case models.TW_TASKSTATUS_COMPLETED:
+		if task.CompletedAt == nil || *task.CompletedAt == "" {
+			return []string{"status:completed", "end:now"}, nil
+		}
+		end, err := models.ConvDateTimeToTW(task.CompletedAt)
+		if err != nil {
+			return nil, err
+		}
+		return []string{"status:completed", "end:" + end}, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf(
+		"[statusModifications] Status '%v' of task '%s' is not supported.",
+		task.Status,
+		*task.Title,
+	))
+}
+
+// dueModification returns the Taskwarrior 'due' attribute of the given task. If the
+// task has no due date, the attribute is cleared.
+func dueModification(task *models.Task) (string, error) {
+	if task.DueAt == nil || *task.DueAt == "" {
+		return "due:", nil
+	}
+
+	due, err := models.ConvDateTimeToTW(task.DueAt)
+	if err != nil {
+		return "", err
+	}
+	return "due:" + due, nil
 }
 
 // CreateUDA creates a User Defined Attribute (UDA) in Taskwarrior.
 func CreateUDA(name string, label string) (err error) {
-	// 'echo "yes"' is required to answer the prompt 'Are you sure?'.
-	out, err := exec.Command("bash", "-c",
-		fmt.Sprintf("'yes' | task config uda.%s.type string", name)).CombinedOutput()
+	// 'rc.confirmation=off' answers the prompt 'Are you sure?'.
+	out, err := taskCommand(
+		"rc.confirmation=off",
+		"config",
+		fmt.Sprintf("uda.%s.type", name),
+		"string",
+	).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf(
 			"[CreateUDAs] Failed to create UDA '%s': %w\nOutput of command: %s\n",
@@ -97,8 +316,10 @@ func CreateUDA(name string, label string) (err error) {
 			out,
 		)
 	}
-	out, err = exec.Command("bash", "-c",
-		fmt.Sprintf("'yes' | task config uda.%s.label %s", name, label)).CombinedOutput()
+	out, err = taskCommand(append(
+		[]string{"rc.confirmation=off", "config", fmt.Sprintf("uda.%s.label", name)},
+		strings.Fields(label)...,
+	)...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf(
 			"[CreateUDAs] Failed to create UDA '%s': %w\nOutput of command: %s\n",
@@ -113,14 +334,138 @@ func CreateUDA(name string, label string) (err error) {
 
 func ReadTasksAll() (*[]models.TaskwarriorTask, error) {
 	// Get JSON representation of all tasks with an MS To-Do Task ID.
-	cmdExport := fmt.Sprintf("task %s.any: export", models.UDANameTodoTaskID)
+	tasksJSON, err := exportTasksJSON(models.UDANameTodoTaskID + ".any:")
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := parseTasksFromJSON(tasksJSON)
+	if err != nil {
+		return nil, err
+	}
+	if !config.ChecklistItems {
+		return tasks, nil
+	}
+
+	checklistItemTasks, err := readChecklistItemTasks()
+	if err != nil {
+		return nil, err
+	}
+	for i, taskJSON := range *tasksJSON {
+		(*tasks)[i].ChecklistItems = []models.ChecklistItem{}
+		for _, uuid := range parseDependsFromJSON(&taskJSON) {
+			if itemTask, ok := checklistItemTasks[uuid]; ok {
+				(*tasks)[i].ChecklistItems = append(
+					(*tasks)[i].ChecklistItems,
+					itemTask.ChecklistItem,
+				)
+			}
+		}
+	}
+
+	return tasks, nil
+}
+
+// ReadTaskByUUID returns the Taskwarrior task with the given UUID.
+func ReadTaskByUUID(uuid *string) (*models.TaskwarriorTask, error) {
+	tasks, err := exportTasks(*uuid)
+	if err != nil {
+		return nil, err
+	}
+	if len(*tasks) != 1 {
+		return nil, errors.New(
+			fmt.Sprintf("[ReadTaskByUUID] Task with UUID '%s' does not exist.", *uuid))
+	}
+
+	return &(*tasks)[0], nil
+}
+
+// ReadTasksUnlinked returns the pending Taskwarrior tasks that match the given filter
+// and are not yet linked to an MS To-Do task.
+func ReadTasksUnlinked(filter *string) (*[]models.TaskwarriorTask, error) {
+	// Tasks that represent checklist items are linked by their checklist item ID. Child
+	// tasks of recurring tasks are linked once MS To-Do creates their occurrence.
+	twFilter := []string{
+		"status:pending",
+		models.UDANameTodoTaskID + ".none:",
+		models.UDANameTodoListID + ".none:",
+	}
+	// The UDA of the checklist items only has to exist if they are synced. The tasks of
+	// checklist items are excluded by their MS To-Do List ID anyway.
+	if config.ChecklistItems {
+		twFilter = append(twFilter, models.UDANameTodoChecklistItemID+".none:")
+	}
+	if filter != nil && *filter != "" {
+		twFilter = append(twFilter, "(")
+		twFilter = append(twFilter, splitFilter(*filter)...)
+		twFilter = append(twFilter, ")")
+	}
+	return exportTasks(twFilter...)
+}
+
+// ReadProjects returns the projects of the pending Taskwarrior tasks.
+func ReadProjects() ([]string, error) {
+	out, err := taskCommand("_projects").Output()
+	if err != nil {
+		return nil, fmt.Errorf("[ReadProjects] Failed to read projects: %w\n", err)
+	}
+
+	var projects []string
+	for _, project := range strings.Split(string(out), "\n") {
+		if project = strings.TrimSpace(project); project != "" {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
+
+// ReadProjectListIDs returns the IDs of the MS To-Do lists the tasks of a project are
+// linked to.
+func ReadProjectListIDs(project string) ([]string, error) {
+	tasksJSON, err := exportTasksJSON(
+		ProjectFilter(project),
+		models.UDANameTodoListID+".any:",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	listIDs := []string{}
+	found := map[string]bool{}
+	for _, taskJSON := range *tasksJSON {
+		listID, err := parseTaskStringAttrFromJSON(models.UDANameTodoListID, &taskJSON)
+		if err != nil {
+			return nil, err
+		}
+		if !found[listID] {
+			found[listID] = true
+			listIDs = append(listIDs, listID)
+		}
+	}
+	return listIDs, nil
+}
+
+// exportTasks returns the Taskwarrior tasks matching the given filter.
+func exportTasks(filter ...string) (*[]models.TaskwarriorTask, error) {
+	tasksJSON, err := exportTasksJSON(filter...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTasksFromJSON(tasksJSON)
+}
+
+// exportTasksJSON returns the JSON representation of the Taskwarrior tasks matching the
+// given filter.
+func exportTasksJSON(filter ...string) (*[]map[string]interface{}, error) {
+	cmdExport := taskCommand(append(filter, "export")...)
 	// If a TASKRC or TASKDATA override is active for Taskwarrior, for example when
 	// running unit tests, additional lines are printed to stderr to show the overrides
 	// used for the export. Thus, only use Output() instead of CombinedOutput().
-	tasksJSONExport, err := exec.Command("bash", "-c", cmdExport).Output()
+	tasksJSONExport, err := cmdExport.Output()
 	if err != nil {
 		return nil, fmt.Errorf(
-			"[GetAllToDoTasks] Failed to get JSON representation of tasks: %w\n"+
+			"[exportTasks] Failed to get JSON representation of tasks: %w\n"+
 				"Output of command: %s\n",
 			err,
 			string(tasksJSONExport),
@@ -131,14 +476,14 @@ func ReadTasksAll() (*[]models.TaskwarriorTask, error) {
 	err = json.Unmarshal(tasksJSONExport, &tasksJSON)
 	if err != nil {
 		return nil, fmt.Errorf(
-			"[GetAllToDoTasks] Failed to unmarshall JSON representation of tasks: %w\n"+
+			"[exportTasks] Failed to unmarshall JSON representation of tasks: %w\n"+
 				"Run '%s' to get the JSON.\n",
 			err,
-			cmdExport,
+			strings.Join(cmdExport.Args, " "),
 		)
 	}
 
-	return parseTasksFromJSON(&tasksJSON)
+	return &tasksJSON, nil
 }
 
 func parseTaskStringAttrFromJSON(
@@ -159,12 +504,46 @@ func parseTaskStringAttrFromJSON(
 	return attr, nil
 }
 
+// parseTaskOptionalStringAttrFromJSON returns an empty string if the attribute is not
+// set for the task.
+func parseTaskOptionalStringAttrFromJSON(
+	attrName string,
+	taskJSON *map[string]interface{},
+) (string, error) {
+	if _, ok := (*taskJSON)[attrName]; !ok {
+		return "", nil
+	}
+
+	return parseTaskStringAttrFromJSON(attrName, taskJSON)
+}
+
+// isWaitingFromJSON returns 'true' if the task has a 'wait' date in the future.
+func isWaitingFromJSON(taskJSON *map[string]interface{}) (bool, error) {
+	taskWait, err := parseTaskOptionalStringAttrFromJSON("wait", taskJSON)
+	if err != nil || taskWait == "" {
+		return false, err
+	}
+
+	waitAt, err := time.Parse(models.TW_DATETIME_FORMAT, taskWait)
+	if err != nil {
+		return false, fmt.Errorf(
+			"[isWaitingFromJSON] Failed to parse 'wait' of task.\n"+
+				"Task JSON: \n%v\n"+
+				"Error: %w",
+			*taskJSON,
+			err,
+		)
+	}
+	return waitAt.After(time.Now()), nil
+}
+
 func parseTasksFromJSON(
 	tasksJSON *[]map[string]interface{},
 ) (*[]models.TaskwarriorTask, error) {
 	var tasks []models.TaskwarriorTask
 	for _, taskJSON := range *tasksJSON {
-		toDoListID, err := parseTaskStringAttrFromJSON(
+		// Tasks that are not yet linked to MS To-Do have no UDAs.
+		toDoListID, err := parseTaskOptionalStringAttrFromJSON(
 			models.UDANameTodoListID,
 			&taskJSON,
 		)
@@ -172,7 +551,7 @@ func parseTasksFromJSON(
 			return nil, err
 		}
 
-		toDoTaskID, err := parseTaskStringAttrFromJSON(
+		toDoTaskID, err := parseTaskOptionalStringAttrFromJSON(
 			models.UDANameTodoTaskID,
 			&taskJSON,
 		)
@@ -205,9 +584,71 @@ func parseTasksFromJSON(
 			)
 		}
 
+		// Depending on its version, Taskwarrior exports a task with a 'wait' date in the
+		// future as 'pending' instead of 'waiting'.
+		if taskStatus == models.TW_TASKSTATUS_PENDING {
+			isWaiting, err := isWaitingFromJSON(&taskJSON)
+			if err != nil {
+				return nil, err
+			}
+			if isWaiting {
+				taskStatus = models.TW_TASKSTATUS_WAITING
+			}
+		}
+
+		// Taskwarrior has no status 'started'. A pending task is started if it has a
+		// 'start' date.
+		if _, ok := taskJSON["start"]; ok && taskStatus == models.TW_TASKSTATUS_PENDING {
+			taskStatus = models.TW_TASKSTATUS_STARTED
+		}
+
 		taskCompletedAt := ""
 		if taskStatus == models.TW_TASKSTATUS_COMPLETED {
-			taskCompletedAt, err = parseTaskStringAttrFromJSON("end", &taskJSON)
+			taskEnd, err := parseTaskStringAttrFromJSON("end", &taskJSON)
+			if err != nil {
+				return nil, err
+			}
+			taskCompletedAt, err = models.ConvDateTimeFromTW(&taskEnd)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		taskDueAt := ""
+		taskDue, err := parseTaskOptionalStringAttrFromJSON("due", &taskJSON)
+		if err != nil {
+			return nil, err
+		}
+		if taskDue != "" {
+			taskDueAt, err = models.ConvDateTimeFromTW(&taskDue)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		taskPriority, err := parseTaskOptionalStringAttrFromJSON("priority", &taskJSON)
+		if err != nil {
+			return nil, err
+		}
+		taskImportance := convPriorityToImportance(taskPriority)
+
+		taskNote := parseNoteFromJSON(&taskJSON)
+
+		taskCategories := parseCategoriesFromJSON(&taskJSON)
+
+		taskModified, err := parseTaskStringAttrFromJSON("modified", &taskJSON)
+		if err != nil {
+			return nil, err
+		}
+		taskModifiedAt, err := time.Parse(models.TW_DATETIME_FORMAT, taskModified)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"[GetAllToDoTasks] Failed to parse 'modified' of task.\n"+
+					"Task JSON: \n%v\n"+
+					"Error: %w",
+				taskJSON,
+				err,
+			)
 		}
 
 		tasks = append(tasks, models.TaskwarriorTask{
@@ -217,14 +658,19 @@ func parseTasksFromJSON(
 				ToDoTaskID:  &toDoTaskID,
 				Title:       &taskDescr,
 				CompletedAt: &taskCompletedAt,
+				DueAt:       &taskDueAt,
+				Importance:  &taskImportance,
+				Note:        &taskNote,
+				Categories:  taskCategories,
 				Status:      taskStatus,
+				ModifiedAt:  &taskModifiedAt,
 			},
 		})
 	}
 	return &tasks, nil
 }
 
-func update(task *models.TaskwarriorTask) error {
+func update(task *models.TaskwarriorTask, defaults *ListDefaults) error {
 	if task.TaskWarriorUUID == nil ||
 		*task.TaskWarriorUUID == "" {
 		return errors.New(
@@ -232,23 +678,40 @@ func update(task *models.TaskwarriorTask) error {
 				*task.Title))
 	}
 
+	currentTask, err := ReadTaskByUUID(task.TaskWarriorUUID)
+	if err != nil {
+		return err
+	}
+
+	statusMods, err := statusModifications(
+		&task.Task,
+		currentTask.Status == models.TW_TASKSTATUS_STARTED,
+	)
+	if err != nil {
+		return err
+	}
+	dueMod, err := dueModification(&task.Task)
+	if err != nil {
+		return err
+	}
+
 	// The output is:
 	//   Modifying task <ID and changed fields>
 	//   Modified 1 task.
-	cmd := exec.Command(
-		"bash",
-		"-c",
-		fmt.Sprintf(
-			"task %s modify '%s' %s:'%s' %s:'%s'",
-			*task.TaskWarriorUUID,
-			*task.Title,
-			models.UDANameTodoListID,
-			*task.ToDoListID,
-			models.UDANameTodoTaskID,
-			*task.ToDoTaskID,
-		))
+	args := []string{
+		// Only modify this task if it is a child of a recurring task.
+		"rc.recurrence.confirmation=no",
+		*task.TaskWarriorUUID,
+		"modify",
+		attrArg(models.UDANameTodoListID, *task.ToDoListID),
+		attrArg(models.UDANameTodoTaskID, *task.ToDoTaskID),
+	}
+	args = append(args, statusMods...)
+	args = append(args, dueMod, "priority:"+defaults.priority(task.Importance))
+	// The title is not parsed by Taskwarrior, for example 'project:' or '+tag'.
+	args = append(args, "--", *task.Title)
 
-	err := cmd.Run()
+	err = taskCommand(args...).Run()
 	if err != nil {
 		return fmt.Errorf(
 			"[update] Failed to update task: %w\n",
@@ -256,6 +719,51 @@ func update(task *models.TaskwarriorTask) error {
 		)
 	}
 
+	// The note and the tags are not changed by the modification above.
+	err = updateNote(task.TaskWarriorUUID, currentTask.Note, task.Note)
+	if err != nil {
+		return err
+	}
+
+	err = updateTags(
+		task.TaskWarriorUUID,
+		currentTask.Categories,
+		task.Categories,
+		defaults,
+	)
+	if err != nil {
+		return err
+	}
+
+	return updateChecklistItems(task.TaskWarriorUUID, &task.Task)
+}
+
+// link stores the MS To-Do list and task ID as UDAs in an existing Taskwarrior task.
+func link(task *models.TaskwarriorTask) error {
+	if task.TaskWarriorUUID == nil ||
+		*task.TaskWarriorUUID == "" {
+		return errors.New(
+			fmt.Sprintf("[link] Cannot link task '%s': Empty UUID",
+				*task.Title))
+	}
+
+	cmd := taskCommand(
+		"rc.recurrence.confirmation=no",
+		*task.TaskWarriorUUID,
+		"modify",
+		attrArg(models.UDANameTodoListID, *task.ToDoListID),
+		attrArg(models.UDANameTodoTaskID, *task.ToDoTaskID),
+	)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"[link] Failed to link task: %w\nOutput of command: %s\n",
+			err,
+			out,
+		)
+	}
+
 	return nil
 }
 
@@ -264,16 +772,20 @@ func UDAExists(udaName string) (bool, error) {
 		return false, errors.New("Cannot check UDA existence. Provided UDA is empty.")
 	}
 
-	err := exec.Command("bash", "-c", fmt.Sprintf("task udas | grep %s", udaName)).Run()
+	out, err := taskCommand("udas").Output()
 	if err != nil {
-		if err.(*exec.ExitError).ExitCode() == 1 {
-			return false, nil
-		}
+		return false, fmt.Errorf("[UDAExists] Failed to read UDAs: %w\n", err)
+	}
 
-		return false, err
+	// The output lists a UDA per line, starting with its name.
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == udaName {
+			return true, nil
+		}
 	}
 
-	return true, nil
+	return false, nil
 }
 
 // CreateIntegrationUDAs creates the Taskwarrior User-Defined-Attributes (UDAs) that are required
@@ -291,5 +803,14 @@ func CreateIntegrationUDAs() error {
 		return err
 	}
 
+	fmt.Printf(
+		"[CreateIntegrationUDAs] Creating UDA %s.\n",
+		models.UDANameTodoChecklistItemID,
+	)
+	err = CreateUDA(models.UDANameTodoChecklistItemID, "MS To-Do Checklist Item ID")
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"math/rand"
 	"os/exec"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/simachri/taskwarrior-ms-todo/internal/models"
 	testUtils "github.com/simachri/taskwarrior-ms-todo/internal/test"
@@ -83,7 +85,11 @@ func TestTaskDelete_taskExists_isFalse(t *testing.T) {
 	toDoListID := generateRandomString(10)
 	toDoTaskID := generateRandomString(10)
 
-	uuid, err := createTask(&taskTitle, &toDoListID, &toDoTaskID)
+	uuid, err := createTask(&models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+	}, nil)
 	assert.NoError(t, err)
 
 	cmdStr := fmt.Sprintf(
@@ -144,7 +150,11 @@ func TestCreateTask_isOK(t *testing.T) {
 	taskTitle := "foo"
 	toDoListID := generateRandomString(10)
 	toDoTaskID := generateRandomString(10)
-	taskUUID, err := createTask(&taskTitle, &toDoListID, &toDoTaskID)
+	taskUUID, err := createTask(&models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+	}, nil)
 	assert.NoError(
 		t,
 		err,
@@ -164,7 +174,11 @@ func TestTaskExists_exists_returnsTrue(t *testing.T) {
 	taskTitle := "foo"
 	toDoListID := generateRandomString(10)
 	toDoTaskID := generateRandomString(10)
-	createTask(&taskTitle, &toDoListID, &toDoTaskID)
+	createTask(&models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+	}, nil)
 
 	exists, err := taskExists(&toDoListID, &toDoTaskID)
 
@@ -175,6 +189,33 @@ func TestTaskExists_exists_returnsTrue(t *testing.T) {
 	assert.True(t, exists)
 }
 
+func TestTaskExists_waitingAndCompleted_returnsTrue(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	for _, status := range []models.TaskStatus{
+		models.TW_TASKSTATUS_WAITING,
+		models.TW_TASKSTATUS_COMPLETED,
+	} {
+		taskTitle := "foo"
+		toDoListID := generateRandomString(10)
+		toDoTaskID := generateRandomString(10)
+		_, err = createTask(&models.Task{
+			Title:      &taskTitle,
+			ToDoListID: &toDoListID,
+			ToDoTaskID: &toDoTaskID,
+			Status:     status,
+		}, nil)
+		assert.NoError(t, err)
+
+		exists, err := taskExists(&toDoListID, &toDoTaskID)
+
+		assert.NoError(t, err)
+		assert.True(t, exists, "Task with status '%v' must exist.", status)
+	}
+}
+
 func TestCreateTask_taskHasUDAs(t *testing.T) {
 	testUtils.NewTaskwarriorEnv(t)
 	err := CreateIntegrationUDAs()
@@ -183,7 +224,11 @@ func TestCreateTask_taskHasUDAs(t *testing.T) {
 	taskTitle := "foo"
 	toDoListID := generateRandomString(10)
 	toDoTaskID := generateRandomString(10)
-	taskUUID, _ := createTask(&taskTitle, &toDoListID, &toDoTaskID)
+	taskUUID, _ := createTask(&models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+	}, nil)
 
 	cmd := fmt.Sprintf("task _get %s.%s", taskUUID, models.UDANameTodoListID)
 	out, err := exec.Command("bash", "-c", cmd).
@@ -220,8 +265,16 @@ func TestReadTasksAll_isOK(t *testing.T) {
 	toDoListID := generateRandomString(10)
 	toDoTaskIDA := generateRandomString(10)
 	toDoTaskIDB := generateRandomString(10)
-	createTask(&taskTitleA, &toDoListID, &toDoTaskIDA)
-	createTask(&taskTitleB, &toDoListID, &toDoTaskIDB)
+	createTask(&models.Task{
+		Title:      &taskTitleA,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskIDA,
+	}, nil)
+	createTask(&models.Task{
+		Title:      &taskTitleB,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskIDB,
+	}, nil)
 
 	tasks, err := ReadTasksAll()
 
@@ -238,3 +291,395 @@ func TestReadTasksAll_isOK(t *testing.T) {
 	assert.Equal(t, toDoTaskIDB, *(*tasks)[1].ToDoTaskID)
 	assert.Equal(t, models.TW_TASKSTATUS_PENDING, (*tasks)[1].Status)
 }
+
+func TestReadTasksUnlinked_linkedTaskExists_isSkipped(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	createTask(&models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+	}, nil)
+
+	unlinkedTitle := "bar"
+	err = exec.Command("bash", "-c",
+		fmt.Sprintf("task add '%s' project:work", unlinkedTitle)).Run()
+	assert.NoError(t, err)
+	err = exec.Command("bash", "-c", "task add baz project:home").Run()
+	assert.NoError(t, err)
+
+	filter := "project:work"
+	tasks, err := ReadTasksUnlinked(&filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*tasks))
+	assert.Equal(t, unlinkedTitle, *(*tasks)[0].Title)
+	assert.Equal(t, "", *(*tasks)[0].ToDoTaskID)
+	assert.NotEmpty(t, *(*tasks)[0].TaskWarriorUUID)
+}
+
+func TestLink_taskHasUDAs(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	err = exec.Command("bash", "-c", fmt.Sprintf("task add '%s'", taskTitle)).Run()
+	assert.NoError(t, err)
+	filter := ""
+	tasks, err := ReadTasksUnlinked(&filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*tasks))
+
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	err = Link(&models.TaskwarriorTask{
+		TaskWarriorUUID: (*tasks)[0].TaskWarriorUUID,
+		Task: models.Task{
+			ToDoListID: &toDoListID,
+			ToDoTaskID: &toDoTaskID,
+			Title:      &taskTitle,
+		},
+	})
+	assert.NoError(t, err)
+
+	exists, err := taskExists(&toDoListID, &toDoTaskID)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	tasks, err = ReadTasksUnlinked(&filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(*tasks))
+}
+
+func TestCreateTask_statuses_areReadBack(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	completedAt := "2022-08-02T13:45:01.0000000"
+	statuses := []models.TaskStatus{
+		models.TW_TASKSTATUS_PENDING,
+		models.TW_TASKSTATUS_STARTED,
+		models.TW_TASKSTATUS_WAITING,
+		models.TW_TASKSTATUS_COMPLETED,
+	}
+	toDoListID := generateRandomString(10)
+	for _, status := range statuses {
+		taskTitle := "foo"
+		toDoTaskID := generateRandomString(10)
+		_, err := createTask(&models.Task{
+			Title:       &taskTitle,
+			ToDoListID:  &toDoListID,
+			ToDoTaskID:  &toDoTaskID,
+			Status:      status,
+			CompletedAt: &completedAt,
+		}, nil)
+		assert.NoError(t, err)
+	}
+
+	tasks, err := ReadTasksAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(statuses), len(*tasks))
+	for i, status := range statuses {
+		assert.Equal(t, status, (*tasks)[i].Status)
+	}
+	assert.Equal(t, completedAt, *(*tasks)[3].CompletedAt)
+}
+
+func TestCreateTask_due_isReadBack(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	dueAt := "2022-08-02T22:00:00.0000000"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	_, err = createTask(&models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		DueAt:      &dueAt,
+	}, nil)
+	assert.NoError(t, err)
+
+	tasks, err := ReadTasksAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*tasks))
+	assert.Equal(t, dueAt, *(*tasks)[0].DueAt)
+}
+
+func TestCreateTask_importance_isReadBack(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	importance := models.TODO_IMPORTANCE_HIGH
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	taskUUID, err := createTask(&models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		Importance: &importance,
+	}, nil)
+	assert.NoError(t, err)
+
+	out, err := exec.Command("bash", "-c", fmt.Sprintf("task _get %s.priority", taskUUID)).
+		Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "H\n", string(out))
+
+	tasks, err := ReadTasksAll()
+	assert.NoError(t, err)
+	assert.Equal(t, importance, *(*tasks)[0].Importance)
+}
+
+func TestUpdate_noteChanged_annotationIsReplaced(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	note := "first note"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	task := models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		Note:       &note,
+	}
+	taskUUID, err := createTask(&task, nil)
+	assert.NoError(t, err)
+
+	updatedNote := "second note"
+	task.Note = &updatedNote
+	err = Update(&models.TaskwarriorTask{Task: task, TaskWarriorUUID: &taskUUID}, nil)
+	assert.NoError(t, err)
+	err = Update(&models.TaskwarriorTask{Task: task, TaskWarriorUUID: &taskUUID}, nil)
+	assert.NoError(t, err)
+
+	out, err := exec.Command("bash", "-c", fmt.Sprintf("task %s export", taskUUID)).
+		Output()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(out), noteAnnotationPrefix))
+
+	updatedTask, err := ReadTaskByUUID(&taskUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, updatedNote, *updatedTask.Note)
+}
+
+func TestUpdate_categoriesChanged_syncedTagsAreReplaced(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	task := models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		Categories: []string{"Red category", "work"},
+	}
+	taskUUID, err := createTask(&task, nil)
+	assert.NoError(t, err)
+
+	task.Categories = []string{"work", "home"}
+	err = Update(&models.TaskwarriorTask{Task: task, TaskWarriorUUID: &taskUUID}, nil)
+	assert.NoError(t, err)
+
+	out, err := exec.Command("bash", "-c", fmt.Sprintf("task _get %s.tags", taskUUID)).
+		Output()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"work", "home"},
+		strings.Split(strings.TrimSpace(string(out)), ","))
+}
+
+func TestCreateTask_listDefaults_areSet(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	task := models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		Categories: []string{"work"},
+	}
+	defaults := &ListDefaults{Project: "home", Tags: []string{"shopping"}}
+	taskUUID, err := createTask(&task, defaults)
+	assert.NoError(t, err)
+
+	// The default tag is kept although it is no MS To-Do category.
+	err = Update(&models.TaskwarriorTask{Task: task, TaskWarriorUUID: &taskUUID}, defaults)
+	assert.NoError(t, err)
+
+	out, err := exec.Command("bash", "-c", fmt.Sprintf("task _get %s.project", taskUUID)).
+		Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "home\n", string(out))
+	out, err = exec.Command("bash", "-c", fmt.Sprintf("task _get %s.tags", taskUUID)).
+		Output()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"work", "shopping"},
+		strings.Split(strings.TrimSpace(string(out)), ","))
+}
+
+func TestImport_titleWithParserSyntax_isKeptVerbatim(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	toDoListID := generateRandomString(10)
+	titles := []string{
+		"Call Bob's dentist",
+		"Buy milk project:home +urgent -- today",
+		`Check "$(touch pwned)" and \n`,
+	}
+	for _, title := range titles {
+		taskTitle := title
+		toDoTaskID := generateRandomString(10)
+		_, err = Import(&models.Task{
+			Title:      &taskTitle,
+			ToDoListID: &toDoListID,
+			ToDoTaskID: &toDoTaskID,
+		}, nil)
+		assert.NoError(t, err)
+	}
+
+	tasks, err := ReadTasksAll()
+	if assert.NoError(t, err) {
+		readTitles := []string{}
+		for _, task := range *tasks {
+			readTitles = append(readTitles, *task.Title)
+		}
+		assert.ElementsMatch(t, titles, readTitles)
+	}
+	projects, err := ReadProjects()
+	assert.NoError(t, err)
+	assert.Empty(t, projects)
+}
+
+func TestUpdate_titleWithApostrophe_isKeptVerbatim(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	task := models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+	}
+	taskUUID, err := createTask(&task, nil)
+	assert.NoError(t, err)
+
+	updatedTitle := "Call Bob's dentist +urgent"
+	task.Title = &updatedTitle
+	err = Update(&models.TaskwarriorTask{Task: task, TaskWarriorUUID: &taskUUID}, nil)
+	assert.NoError(t, err)
+
+	updatedTask, err := ReadTaskByUUID(&taskUUID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, updatedTitle, *updatedTask.Title)
+		assert.Empty(t, updatedTask.Categories)
+	}
+}
+
+func TestParseTasksFromJSON_futureWait_isWaiting(t *testing.T) {
+	newTaskJSON := func(status string, wait string) map[string]interface{} {
+		taskJSON := map[string]interface{}{
+			"uuid":        "5a1f0e2c-3b4d-4e6f-8a9b-0c1d2e3f4a5b",
+			"description": "foo",
+			"status":      status,
+			"modified":    "20220801T120000Z",
+		}
+		if wait != "" {
+			taskJSON["wait"] = wait
+		}
+		return taskJSON
+	}
+	future := time.Now().Add(24 * time.Hour).UTC().Format(models.TW_DATETIME_FORMAT)
+	past := time.Now().Add(-24 * time.Hour).UTC().Format(models.TW_DATETIME_FORMAT)
+
+	tasks, err := parseTasksFromJSON(&[]map[string]interface{}{
+		newTaskJSON("pending", future),
+		newTaskJSON("waiting", future),
+		newTaskJSON("pending", past),
+		newTaskJSON("pending", ""),
+	})
+
+	if assert.NoError(t, err) && assert.Len(t, *tasks, 4) {
+		assert.Equal(t, models.TW_TASKSTATUS_WAITING, (*tasks)[0].Status)
+		assert.Equal(t, models.TW_TASKSTATUS_WAITING, (*tasks)[1].Status)
+		assert.Equal(t, models.TW_TASKSTATUS_PENDING, (*tasks)[2].Status)
+		assert.Equal(t, models.TW_TASKSTATUS_PENDING, (*tasks)[3].Status)
+	}
+}
+
+func TestStatusModifications_started_keepsStartOfStartedTask(t *testing.T) {
+	task := &models.Task{Status: models.TW_TASKSTATUS_STARTED}
+
+	mods, err := statusModifications(task, false)
+	assert.NoError(t, err)
+	assert.Contains(t, mods, "start:now")
+
+	mods, err = statusModifications(task, true)
+	assert.NoError(t, err)
+	for _, mod := range mods {
+		assert.NotContains(t, mod, "start:")
+	}
+}
+
+func TestUpdate_startedTask_keepsStartDate(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "foo"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	task := models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		Status:     models.TW_TASKSTATUS_STARTED,
+	}
+	taskUUID, err := createTask(&task, nil)
+	assert.NoError(t, err)
+	tasksJSON, err := exportTasksJSON(taskUUID)
+	if !assert.NoError(t, err) || !assert.Len(t, *tasksJSON, 1) {
+		return
+	}
+	start := (*tasksJSON)[0]["start"]
+	// 'start' has a precision of seconds.
+	time.Sleep(1100 * time.Millisecond)
+
+	updatedTitle := "bar"
+	task.Title = &updatedTitle
+	err = update(&models.TaskwarriorTask{Task: task, TaskWarriorUUID: &taskUUID}, nil)
+	assert.NoError(t, err)
+
+	tasksJSON, err = exportTasksJSON(taskUUID)
+	if assert.NoError(t, err) && assert.Len(t, *tasksJSON, 1) {
+		assert.Equal(t, start, (*tasksJSON)[0]["start"])
+		assert.Equal(t, "bar", (*tasksJSON)[0]["description"])
+	}
+}
@@ -0,0 +1,233 @@
+package taskwarrior
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+)
+
+// checklistItemTask is a Taskwarrior task that represents a checklist item of a MS
+// To-Do task.
+type checklistItemTask struct {
+	models.ChecklistItem
+	TaskWarriorUUID string
+}
+
+// parseDependsFromJSON returns the UUIDs of the tasks a task depends on. Taskwarrior 2.6
+// exports 'depends' as array, older versions as comma-separated string.
+func parseDependsFromJSON(taskJSON *map[string]interface{}) []string {
+	var uuids []string
+	switch depends := (*taskJSON)["depends"].(type) {
+	case []interface{}:
+		for _, uuid := range depends {
+			if uuidStr, ok := uuid.(string); ok {
+				uuids = append(uuids, uuidStr)
+			}
+		}
+	case string:
+		for _, uuid := range strings.Split(depends, ",") {
+			if uuid != "" {
+				uuids = append(uuids, uuid)
+			}
+		}
+	}
+	return uuids
+}
+
+// readChecklistItemTasks returns the Taskwarrior tasks that represent checklist items.
+// The key is the UUID of the Taskwarrior task.
+func readChecklistItemTasks() (map[string]checklistItemTask, error) {
+	tasksJSON, err := exportTasksJSON(
+		models.UDANameTodoChecklistItemID+".any:",
+		"status.not:deleted",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	itemTasks := map[string]checklistItemTask{}
+	for _, taskJSON := range *tasksJSON {
+		uuid, err := parseTaskStringAttrFromJSON("uuid", &taskJSON)
+		if err != nil {
+			return nil, err
+		}
+		itemID, err := parseTaskStringAttrFromJSON(
+			models.UDANameTodoChecklistItemID,
+			&taskJSON,
+		)
+		if err != nil {
+			return nil, err
+		}
+		title, err := parseTaskStringAttrFromJSON("description", &taskJSON)
+		if err != nil {
+			return nil, err
+		}
+		status, err := parseTaskStringAttrFromJSON("status", &taskJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		itemTasks[uuid] = checklistItemTask{
+			TaskWarriorUUID: uuid,
+			ChecklistItem: models.ChecklistItem{
+				ToDoChecklistItemID: &itemID,
+				Title:               &title,
+				IsChecked:           status == "completed",
+			},
+		}
+	}
+
+	return itemTasks, nil
+}
+
+// updateChecklistItems creates, updates and deletes the Taskwarrior tasks that represent
+// the checklist items of a task such that they match the MS To-Do checklist items. The
+// task depends on all of its checklist item tasks.
+func updateChecklistItems(taskUUID *string, task *models.Task) error {
+	if !config.ChecklistItems || task.ChecklistItems == nil {
+		return nil
+	}
+
+	tasksJSON, err := exportTasksJSON(*taskUUID)
+	if err != nil {
+		return err
+	}
+	if len(*tasksJSON) != 1 {
+		return errors.New(fmt.Sprintf(
+			"[updateChecklistItems] Task with UUID '%s' does not exist.", *taskUUID))
+	}
+
+	allItemTasks, err := readChecklistItemTasks()
+	if err != nil {
+		return err
+	}
+	// Key is the MS To-Do Checklist Item ID.
+	itemTasks := map[string]checklistItemTask{}
+	for _, uuid := range parseDependsFromJSON(&(*tasksJSON)[0]) {
+		if itemTask, ok := allItemTasks[uuid]; ok {
+			itemTasks[*itemTask.ToDoChecklistItemID] = itemTask
+		}
+	}
+
+	var createdUUIDs []string
+	for _, item := range task.ChecklistItems {
+		itemTask, ok := itemTasks[*item.ToDoChecklistItemID]
+		if !ok {
+			uuid, err := createChecklistItemTask(task.ToDoListID, &item)
+			if err != nil {
+				return err
+			}
+			createdUUIDs = append(createdUUIDs, uuid)
+			continue
+		}
+
+		delete(itemTasks, *item.ToDoChecklistItemID)
+		if *itemTask.Title != *item.Title || itemTask.IsChecked != item.IsChecked {
+			err = updateChecklistItemTask(&itemTask.TaskWarriorUUID, &item)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(createdUUIDs) > 0 {
+		out, err := taskCommand(
+			*taskUUID,
+			"modify",
+			"depends:"+strings.Join(createdUUIDs, ","),
+		).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf(
+				"[updateChecklistItems] Failed to add dependencies to task '%s': %w\n"+
+					"Output of command: %s\n",
+				*taskUUID,
+				err,
+				out,
+			)
+		}
+	}
+
+	// The remaining checklist items have been removed in MS To-Do.
+	for _, itemTask := range itemTasks {
+		out, err := taskCommand(
+			"rc.confirmation=off",
+			itemTask.TaskWarriorUUID,
+			"delete",
+		).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf(
+				"[updateChecklistItems] Failed to delete checklist item task '%s': %w\n"+
+					"Output of command: %s\n",
+				itemTask.TaskWarriorUUID,
+				err,
+				out,
+			)
+		}
+	}
+
+	return nil
+}
+
+// createChecklistItemTask creates the Taskwarrior task for a checklist item. The task
+// is created as pending and completed afterwards if the item is checked such that its
+// ID can be extracted.
+func createChecklistItemTask(
+	toDoListID *string,
+	item *models.ChecklistItem,
+) (taskUUID string, err error) {
+	out, err := taskCommand(
+		"rc.verbose=new-uuid",
+		"add",
+		attrArg(models.UDANameTodoListID, *toDoListID),
+		attrArg(models.UDANameTodoChecklistItemID, *item.ToDoChecklistItemID),
+		"--",
+		*item.Title,
+	).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf(
+			"[createChecklistItemTask] Failed to create task: %w\n"+
+				"Output of command: %s\n",
+			err,
+			out,
+		)
+	}
+
+	taskUUID, err = parseCreatedUUID(out)
+	if err != nil {
+		return "", err
+	}
+
+	if item.IsChecked {
+		err = updateChecklistItemTask(&taskUUID, item)
+		if err != nil {
+			return taskUUID, err
+		}
+	}
+
+	return taskUUID, nil
+}
+
+// updateChecklistItemTask sets the description and the status of the Taskwarrior task
+// of a checklist item.
+func updateChecklistItemTask(taskUUID *string, item *models.ChecklistItem) error {
+	statusMods := []string{"status:pending", "end:"}
+	if item.IsChecked {
+		statusMods = []string{"status:completed", "end:now"}
+	}
+
+	args := append([]string{*taskUUID, "modify"}, statusMods...)
+	out, err := taskCommand(append(args, "--", *item.Title)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"[updateChecklistItemTask] Failed to update task '%s': %w\n"+
+				"Output of command: %s\n",
+			*taskUUID,
+			err,
+			out,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package taskwarrior
+
+import (
+	"testing"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	testUtils "github.com/simachri/taskwarrior-ms-todo/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDependsFromJSON_arrayAndString(t *testing.T) {
+	taskJSON := map[string]interface{}{"depends": []interface{}{"a", "b"}}
+	assert.Equal(t, []string{"a", "b"}, parseDependsFromJSON(&taskJSON))
+
+	taskJSON = map[string]interface{}{"depends": "a,b"}
+	assert.Equal(t, []string{"a", "b"}, parseDependsFromJSON(&taskJSON))
+
+	taskJSON = map[string]interface{}{}
+	assert.Empty(t, parseDependsFromJSON(&taskJSON))
+}
+
+func TestCreateTask_checklistItems_areReadBack(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+	checklistConfig := DefaultConfig()
+	checklistConfig.ChecklistItems = true
+	Configure(checklistConfig)
+	defer Configure(DefaultConfig())
+
+	taskTitle := "foo"
+	itemIDA := generateRandomString(10)
+	itemIDB := generateRandomString(10)
+	itemTitleA := "bar"
+	itemTitleB := "baz"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	task := models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		ChecklistItems: []models.ChecklistItem{
+			{ToDoChecklistItemID: &itemIDA, Title: &itemTitleA, IsChecked: true},
+			{ToDoChecklistItemID: &itemIDB, Title: &itemTitleB, IsChecked: false},
+		},
+	}
+	taskUUID, err := createTask(&task, nil)
+	assert.NoError(t, err)
+
+	tasks, err := ReadTasksAll()
+	assert.NoError(t, err)
+	assert.Len(t, *tasks, 1)
+	assert.True(t, task.IsUpToDate(&(*tasks)[0].Task))
+
+	// Checking the second and removing the first item in MS To-Do.
+	task.ChecklistItems = []models.ChecklistItem{
+		{ToDoChecklistItemID: &itemIDB, Title: &itemTitleB, IsChecked: true},
+	}
+	err = Update(&models.TaskwarriorTask{Task: task, TaskWarriorUUID: &taskUUID}, nil)
+	assert.NoError(t, err)
+
+	tasks, err = ReadTasksAll()
+	assert.NoError(t, err)
+	assert.Len(t, (*tasks)[0].ChecklistItems, 1)
+	assert.True(t, task.IsUpToDate(&(*tasks)[0].Task))
+
+	unlinkedTasks, err := ReadTasksUnlinked(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, *unlinkedTasks)
+}
@@ -0,0 +1,94 @@
+package taskwarrior
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// With 'rc.verbose=new-uuid', 'task add ...' prints 'Created task <UUID>.' instead of
+// the ID of the task.
+var createdUUIDPattern = regexp.MustCompile(
+	`Created task ([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`,
+)
+
+// taskCommand returns the command that runs the 'task' CLI with the given arguments.
+// The arguments are passed to 'task' as they are, no shell is involved. Text that must
+// not be interpreted by the parser of Taskwarrior, for example a description, is passed
+// after the argument '--'.
+func taskCommand(args ...string) *exec.Cmd {
+	return exec.Command("task", args...)
+}
+
+// attrArg returns the Taskwarrior attribute 'name:value' with the value quoted such
+// that the parser of Taskwarrior does not interpret it, for example 'project:'.
+func attrArg(name string, value string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return fmt.Sprintf("%s:'%s'", name, escaper.Replace(value))
+}
+
+// ProjectFilter returns the Taskwarrior filter of the tasks of exactly the given
+// project, without its subprojects.
+func ProjectFilter(project string) string {
+	return attrArg("project.is", project)
+}
+
+// tagArg returns the Taskwarrior argument that adds the tag if 'add' is 'true',
+// otherwise the argument that removes it.
+func tagArg(tag string, add bool) string {
+	if add {
+		return "+" + tag
+	}
+	return "-" + tag
+}
+
+// splitFilter splits a Taskwarrior filter into arguments at whitespace outside of
+// quotes. Quotes and escapes are kept such that the parser of Taskwarrior handles them
+// as if the filter had been typed on the command line.
+func splitFilter(filter string) []string {
+	args := []string{}
+	var arg strings.Builder
+	var quote rune
+	isEscaped := false
+	for _, c := range filter {
+		switch {
+		case isEscaped:
+			isEscaped = false
+		case c == '\\':
+			isEscaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case unicode.IsSpace(c):
+			if arg.Len() > 0 {
+				args = append(args, arg.String())
+				arg.Reset()
+			}
+			continue
+		}
+		arg.WriteRune(c)
+	}
+	if arg.Len() > 0 {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// parseCreatedUUID returns the UUID of the task created by 'task rc.verbose=new-uuid
+// add ...' from the output of the command.
+func parseCreatedUUID(out []byte) (string, error) {
+	match := createdUUIDPattern.FindSubmatch(out)
+	if match == nil {
+		return "", errors.New(fmt.Sprintf(
+			"[parseCreatedUUID] Output of command has no UUID of a created task:\n%s\n",
+			out,
+		))
+	}
+	return string(match[1]), nil
+}
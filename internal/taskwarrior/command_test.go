@@ -0,0 +1,45 @@
+package taskwarrior
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttrArg_quotesAndEscapesValue(t *testing.T) {
+	assert.Equal(t, "project:'home'", attrArg("project", "home"))
+	assert.Equal(t, `project:'Bob\'s \\ home +x'`, attrArg("project", `Bob's \ home +x`))
+}
+
+func TestSplitFilter_quotesAreKept(t *testing.T) {
+	assert.Equal(t,
+		[]string{"project.is:'my project'", "(", "+next", "or", `"due soon"`, ")"},
+		splitFilter(`project.is:'my project'  ( +next or "due soon" )`),
+	)
+	assert.Equal(t, []string{`project.is:'Bob\'s'`}, splitFilter(`project.is:'Bob\'s'`))
+	assert.Empty(t, splitFilter("  "))
+}
+
+func TestParseCreatedUUID(t *testing.T) {
+	uuid, err := parseCreatedUUID(
+		[]byte("Created task 5a1f0e2c-3b4d-4e6f-8a9b-0c1d2e3f4a5b.\n"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "5a1f0e2c-3b4d-4e6f-8a9b-0c1d2e3f4a5b", uuid)
+
+	_, err = parseCreatedUUID([]byte("Created task 42.\n"))
+	assert.Error(t, err)
+}
+
+func TestTagModifications_addedAndRemovedTags(t *testing.T) {
+	Configure(DefaultConfig())
+	defaults := &ListDefaults{Tags: []string{"shopping"}}
+
+	mods := tagModifications(
+		[]string{"home", "shopping"},
+		[]string{"Red category", "work"},
+		defaults,
+	)
+
+	assert.Equal(t, []string{"+Red_category", "+work", "-home"}, mods)
+}
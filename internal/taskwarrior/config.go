@@ -0,0 +1,181 @@
+package taskwarrior
+
+import (
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+)
+
+// Config controls how MS To-Do tasks are represented in Taskwarrior. It is read from
+// the section 'taskwarrior' of the config.yaml.
+type Config struct {
+	// Value of the 'wait' attribute for tasks that are 'waitingOnOthers' or 'deferred' in
+	// MS To-Do, for example 'someday' or 'now+7d'.
+	Wait string
+	// Taskwarrior 'priority' for each MS To-Do importance 'low', 'normal' and 'high'. An
+	// empty priority means that no priority is set.
+	Priority map[string]string
+	// If 'true', the checklist items of a MS To-Do task are imported as Taskwarrior tasks
+	// the task depends on.
+	ChecklistItems bool `mapstructure:"checklist_items"`
+	// Controls which Taskwarrior tags are synced with MS To-Do categories.
+	Tags TagsConfig
+}
+
+// TagsConfig controls which Taskwarrior tags are synced with MS To-Do categories.
+type TagsConfig struct {
+	// Taskwarrior tags that are synced. If empty, all tags are synced.
+	Allowlist []string
+	// Taskwarrior tags for MS To-Do categories. Categories without mapping are synced as
+	// normalized tag, see models.NormalizeTag().
+	Mapping []TagMapping
+}
+
+// TagMapping maps a MS To-Do category to a Taskwarrior tag. It is a list entry instead of
+// a map key as config keys are case-insensitive.
+type TagMapping struct {
+	Category string
+	Tag      string
+}
+
+// ListDefaults are the Taskwarrior attributes of the tasks of a MS To-Do list.
+type ListDefaults struct {
+	// 'project' of the tasks created for the list.
+	Project string
+	// Tags of the tasks created for the list. They are not synced with MS To-Do
+	// categories.
+	Tags []string
+	// 'priority' of the tasks whose MS To-Do importance has no priority.
+	Priority string
+}
+
+var config = DefaultConfig()
+
+// DefaultConfig returns the config that is used if config.yaml has no 'taskwarrior'
+// section.
+func DefaultConfig() Config {
+	return Config{
+		Wait: "someday",
+		Priority: map[string]string{
+			models.TODO_IMPORTANCE_LOW:    "L",
+			models.TODO_IMPORTANCE_NORMAL: "",
+			models.TODO_IMPORTANCE_HIGH:   "H",
+		},
+	}
+}
+
+// Configure sets the config used by all subsequent Taskwarrior operations.
+func Configure(c Config) {
+	config = c
+}
+
+// convImportanceToPriority returns the Taskwarrior priority for a MS To-Do importance.
+func convImportanceToPriority(importance *string) string {
+	if importance == nil {
+		return ""
+	}
+	return config.Priority[*importance]
+}
+
+// convPriorityToImportance returns the MS To-Do importance for a Taskwarrior priority.
+// A priority without mapping is treated as 'normal' importance.
+func convPriorityToImportance(priority string) string {
+	// 'normal' comes first such that it wins if several importances share a priority.
+	importances := []string{
+		models.TODO_IMPORTANCE_NORMAL,
+		models.TODO_IMPORTANCE_HIGH,
+		models.TODO_IMPORTANCE_LOW,
+	}
+	for _, importance := range importances {
+		if mappedPriority, ok := config.Priority[importance]; ok &&
+			mappedPriority == priority {
+			return importance
+		}
+	}
+	return models.TODO_IMPORTANCE_NORMAL
+}
+
+// convCategoryToTag returns the Taskwarrior tag for a MS To-Do category.
+func convCategoryToTag(category string) string {
+	for _, mapping := range config.Tags.Mapping {
+		if mapping.Category == category {
+			return mapping.Tag
+		}
+	}
+	return models.NormalizeTag(category)
+}
+
+// convTagToCategory returns the MS To-Do category for a Taskwarrior tag.
+func convTagToCategory(tag string) string {
+	for _, mapping := range config.Tags.Mapping {
+		if mapping.Tag == tag {
+			return mapping.Category
+		}
+	}
+	return tag
+}
+
+// isTagSynced returns 'true' if the Taskwarrior tag is synced with MS To-Do.
+func isTagSynced(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	if len(config.Tags.Allowlist) == 0 {
+		return true
+	}
+	for _, allowedTag := range config.Tags.Allowlist {
+		if allowedTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCategorySynced returns 'true' if the MS To-Do category is synced with Taskwarrior.
+func IsCategorySynced(category string) bool {
+	return isTagSynced(convCategoryToTag(category))
+}
+
+// AreChecklistItemsSynced returns 'true' if the checklist items of MS To-Do tasks are
+// synced with Taskwarrior.
+func AreChecklistItemsSynced() bool {
+	return config.ChecklistItems
+}
+
+// priority returns the Taskwarrior priority for a MS To-Do importance. If the importance
+// has no priority, the default priority of the list is returned.
+func (defaults *ListDefaults) priority(importance *string) string {
+	priority := convImportanceToPriority(importance)
+	if priority == "" && defaults != nil {
+		return defaults.Priority
+	}
+	return priority
+}
+
+// modifications returns the Taskwarrior attributes 'project' and tags of a task that is
+// created for the list.
+func (defaults *ListDefaults) modifications() []string {
+	if defaults == nil {
+		return nil
+	}
+
+	var mods []string
+	if defaults.Project != "" {
+		mods = append(mods, attrArg("project", defaults.Project))
+	}
+	for _, tag := range defaults.Tags {
+		mods = append(mods, tagArg(tag, true))
+	}
+	return mods
+}
+
+// isDefaultTag returns 'true' if the tag is a default tag of the list.
+func (defaults *ListDefaults) isDefaultTag(tag string) bool {
+	if defaults == nil {
+		return false
+	}
+	for _, defaultTag := range defaults.Tags {
+		if defaultTag == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package taskwarrior
+
+import (
+	"testing"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvPriority_defaultConfig_roundTrip(t *testing.T) {
+	Configure(DefaultConfig())
+
+	for _, importance := range []string{
+		models.TODO_IMPORTANCE_LOW,
+		models.TODO_IMPORTANCE_NORMAL,
+		models.TODO_IMPORTANCE_HIGH,
+	} {
+		priority := convImportanceToPriority(&importance)
+		assert.Equal(t, importance, convPriorityToImportance(priority))
+	}
+}
+
+func TestConvPriorityToImportance_unmapped_isNormal(t *testing.T) {
+	Configure(DefaultConfig())
+
+	assert.Equal(t, models.TODO_IMPORTANCE_NORMAL, convPriorityToImportance("M"))
+}
+
+func TestConvImportanceToPriority_customConfig(t *testing.T) {
+	customConfig := DefaultConfig()
+	customConfig.Priority[models.TODO_IMPORTANCE_NORMAL] = "M"
+	Configure(customConfig)
+	defer Configure(DefaultConfig())
+
+	importance := models.TODO_IMPORTANCE_NORMAL
+	assert.Equal(t, "M", convImportanceToPriority(&importance))
+	assert.Equal(t, models.TODO_IMPORTANCE_NORMAL, convPriorityToImportance("M"))
+	assert.Equal(t, models.TODO_IMPORTANCE_NORMAL, convPriorityToImportance(""))
+}
+
+func TestConvCategoryToTag_mappingAndNormalization(t *testing.T) {
+	customConfig := DefaultConfig()
+	customConfig.Tags.Mapping = []TagMapping{{Category: "Red category", Tag: "urgent"}}
+	Configure(customConfig)
+	defer Configure(DefaultConfig())
+
+	assert.Equal(t, "urgent", convCategoryToTag("Red category"))
+	assert.Equal(t, "Red category", convTagToCategory("urgent"))
+	assert.Equal(t, "Blue_category", convCategoryToTag("Blue category"))
+	assert.Equal(t, "work", convTagToCategory("work"))
+}
+
+func TestIsCategorySynced_allowlist(t *testing.T) {
+	Configure(DefaultConfig())
+	assert.True(t, IsCategorySynced("Blue category"))
+
+	customConfig := DefaultConfig()
+	customConfig.Tags.Allowlist = []string{"urgent", "work"}
+	customConfig.Tags.Mapping = []TagMapping{{Category: "Red category", Tag: "urgent"}}
+	Configure(customConfig)
+	defer Configure(DefaultConfig())
+
+	assert.True(t, IsCategorySynced("Red category"))
+	assert.True(t, IsCategorySynced("work"))
+	assert.False(t, IsCategorySynced("Blue category"))
+}
+
+func TestListDefaults_priorityAndModifications(t *testing.T) {
+	Configure(DefaultConfig())
+	defaults := &ListDefaults{
+		Project:  "home.groceries",
+		Tags:     []string{"shopping"},
+		Priority: "M",
+	}
+	normal := models.TODO_IMPORTANCE_NORMAL
+	high := models.TODO_IMPORTANCE_HIGH
+
+	assert.Equal(t, "M", defaults.priority(&normal))
+	assert.Equal(t, "H", defaults.priority(&high))
+	assert.Equal(t, []string{"project:'home.groceries'", "+shopping"},
+		defaults.modifications())
+
+	var noDefaults *ListDefaults
+	assert.Equal(t, "", noDefaults.priority(&normal))
+	assert.Empty(t, noDefaults.modifications())
+}
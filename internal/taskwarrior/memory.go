@@ -0,0 +1,353 @@
+package taskwarrior
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
+)
+
+var (
+	// memoryProjectFilter matches the only filter of pushed tasks the MemoryStore
+	// supports, see ProjectFilter.
+	memoryProjectFilter = regexp.MustCompile(`^project\.is:'((?:[^'\\]|\\.)*)'$`)
+	// memoryEscape matches a character escaped by attrArg.
+	memoryEscape = regexp.MustCompile(`\\(.)`)
+)
+
+// memoryTask is a task of the MemoryStore with its Taskwarrior project.
+type memoryTask struct {
+	task    models.TaskwarriorTask
+	project string
+}
+
+// MemoryStore is a TaskStore that keeps the tasks in memory, for example to test the
+// sync without the 'task' CLI. Recurring tasks are created without recurrence and
+// filters are only supported in the form of ProjectFilter. The default tags of
+// a list are stored as categories. It is safe for concurrent use.
+type MemoryStore struct {
+	mutex    sync.Mutex
+	tasks    []*memoryTask
+	nextUUID int
+}
+
+// NewMemoryStore returns a MemoryStore without tasks.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// AddTask adds a task of the given project as if it has been created in Taskwarrior and
+// returns its UUID.
+func (store *MemoryStore) AddTask(task models.Task, project string) string {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return *store.add(task, project).task.TaskWarriorUUID
+}
+
+// ModifyTask changes the task with the given UUID as if it has been modified in
+// Taskwarrior.
+func (store *MemoryStore) ModifyTask(uuid string, modify func(task *models.Task)) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	memTask := store.findByUUID(uuid)
+	if memTask == nil {
+		return fmt.Errorf("[MemoryStore] Task with UUID '%s' does not exist.", uuid)
+	}
+	modify(&memTask.task.Task)
+	normalizeMemoryTask(&memTask.task)
+	memTask.task.ModifiedAt = memoryNow()
+	return nil
+}
+
+func (store *MemoryStore) ReadTasksAll() (*[]models.TaskwarriorTask, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	tasks := []models.TaskwarriorTask{}
+	for _, memTask := range store.tasks {
+		if *memTask.task.ToDoTaskID != "" {
+			tasks = append(tasks, copyMemoryTask(&memTask.task))
+		}
+	}
+	return &tasks, nil
+}
+
+func (store *MemoryStore) ReadTaskByUUID(uuid *string) (*models.TaskwarriorTask, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	memTask := store.findByUUID(*uuid)
+	if memTask == nil {
+		return nil, errors.New(
+			fmt.Sprintf("[ReadTaskByUUID] Task with UUID '%s' does not exist.", *uuid))
+	}
+	task := copyMemoryTask(&memTask.task)
+	return &task, nil
+}
+
+func (store *MemoryStore) ReadTasksUnlinked(
+	filter *string,
+) (*[]models.TaskwarriorTask, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	filterProject, isProjectFiltered := "", false
+	if filter != nil && *filter != "" {
+		match := memoryProjectFilter.FindStringSubmatch(*filter)
+		if match == nil {
+			return nil, fmt.Errorf("[MemoryStore] Unsupported filter '%s'.", *filter)
+		}
+		filterProject = memoryEscape.ReplaceAllString(match[1], "$1")
+		isProjectFiltered = true
+	}
+
+	tasks := []models.TaskwarriorTask{}
+	for _, memTask := range store.tasks {
+		if !isPendingStatus(memTask.task.Status) ||
+			*memTask.task.ToDoTaskID != "" ||
+			*memTask.task.ToDoListID != "" ||
+			(isProjectFiltered && memTask.project != filterProject) {
+			continue
+		}
+		tasks = append(tasks, copyMemoryTask(&memTask.task))
+	}
+	return &tasks, nil
+}
+
+func (store *MemoryStore) ReadProjects() ([]string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	projects := []string{}
+	found := map[string]bool{}
+	for _, memTask := range store.tasks {
+		if memTask.project != "" &&
+			isPendingStatus(memTask.task.Status) &&
+			!found[memTask.project] {
+			found[memTask.project] = true
+			projects = append(projects, memTask.project)
+		}
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+func (store *MemoryStore) ReadProjectListIDs(project string) ([]string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	listIDs := []string{}
+	found := map[string]bool{}
+	for _, memTask := range store.tasks {
+		listID := *memTask.task.ToDoListID
+		if memTask.project == project && listID != "" && !found[listID] {
+			found[listID] = true
+			listIDs = append(listIDs, listID)
+		}
+	}
+	return listIDs, nil
+}
+
+func (store *MemoryStore) Import(
+	task *models.Task,
+	defaults *ListDefaults,
+) (ImportResult, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.findByToDoID(task.ToDoListID, task.ToDoTaskID) != nil {
+		return TASK_EXISTS_AND_SKIPPED, nil
+	}
+
+	importedTask := *task
+	importedTask.Recurrence = nil
+	project := ""
+	if defaults != nil {
+		project = defaults.Project
+		importedTask.Categories = withDefaultTags(task.Categories, defaults)
+	}
+	store.add(importedTask, project)
+
+	if task.Recurrence != nil {
+		return TASK_CREATED_WITHOUT_RECURRENCE, nil
+	}
+	return TASK_CREATED, nil
+}
+
+func (store *MemoryStore) Update(task *models.TaskwarriorTask, defaults *ListDefaults) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	memTask := store.findByToDoID(task.ToDoListID, task.ToDoTaskID)
+	if memTask == nil {
+		return errors.New(
+			fmt.Sprintf("[Update] Failed - no Taskwarrior task exists for\n"+
+				"MS To-Do List ID: %s\n"+
+				"MS To-Do Task ID: %s\n", *task.ToDoListID, *task.ToDoTaskID),
+		)
+	}
+	if task.TaskWarriorUUID == nil ||
+		*task.TaskWarriorUUID != *memTask.task.TaskWarriorUUID {
+		return errors.New(
+			fmt.Sprintf("[update] Cannot update task '%s': Wrong UUID", *task.Title))
+	}
+
+	updatedTask := copyMemoryTask(task)
+	updatedTask.Recurrence = nil
+	if updatedTask.Categories == nil {
+		updatedTask.Categories = memTask.task.Categories
+	} else if defaults != nil {
+		updatedTask.Categories = withDefaultTags(task.Categories, defaults)
+	}
+	if updatedTask.Note == nil {
+		updatedTask.Note = memTask.task.Note
+	}
+	if updatedTask.ChecklistItems == nil {
+		updatedTask.ChecklistItems = memTask.task.ChecklistItems
+	}
+	normalizeMemoryTask(&updatedTask)
+	updatedTask.ModifiedAt = memoryNow()
+	memTask.task = updatedTask
+	return nil
+}
+
+func (store *MemoryStore) Link(task *models.TaskwarriorTask) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.findByToDoID(task.ToDoListID, task.ToDoTaskID) != nil {
+		return errors.New(
+			fmt.Sprintf("[Link] Failed - a Taskwarrior task already exists for\n"+
+				"MS To-Do List ID: %s\n"+
+				"MS To-Do Task ID: %s\n", *task.ToDoListID, *task.ToDoTaskID),
+		)
+	}
+	if task.TaskWarriorUUID == nil || store.findByUUID(*task.TaskWarriorUUID) == nil {
+		return errors.New(
+			fmt.Sprintf("[link] Cannot link task '%s': Unknown UUID", *task.Title))
+	}
+
+	memTask := store.findByUUID(*task.TaskWarriorUUID)
+	listID := *task.ToDoListID
+	taskID := *task.ToDoTaskID
+	memTask.task.ToDoListID = &listID
+	memTask.task.ToDoTaskID = &taskID
+	memTask.task.ModifiedAt = memoryNow()
+	return nil
+}
+
+// add adds a task with a new UUID. It is called with the mutex locked.
+func (store *MemoryStore) add(task models.Task, project string) *memoryTask {
+	store.nextUUID++
+	uuid := fmt.Sprintf("00000000-0000-0000-0000-%012d", store.nextUUID)
+
+	memTask := &memoryTask{
+		task:    copyMemoryTask(&models.TaskwarriorTask{Task: task, TaskWarriorUUID: &uuid}),
+		project: project,
+	}
+	normalizeMemoryTask(&memTask.task)
+	memTask.task.ModifiedAt = memoryNow()
+
+	store.tasks = append(store.tasks, memTask)
+	return memTask
+}
+
+func (store *MemoryStore) findByUUID(uuid string) *memoryTask {
+	for _, memTask := range store.tasks {
+		if *memTask.task.TaskWarriorUUID == uuid {
+			return memTask
+		}
+	}
+	return nil
+}
+
+func (store *MemoryStore) findByToDoID(listID *string, taskID *string) *memoryTask {
+	if listID == nil || taskID == nil {
+		return nil
+	}
+	for _, memTask := range store.tasks {
+		if *memTask.task.ToDoListID == *listID && *memTask.task.ToDoTaskID == *taskID {
+			return memTask
+		}
+	}
+	return nil
+}
+
+// copyMemoryTask copies a task such that the caller cannot change the stored task.
+func copyMemoryTask(task *models.TaskwarriorTask) models.TaskwarriorTask {
+	taskCopy := *task
+	if task.Categories != nil {
+		taskCopy.Categories = append([]string{}, task.Categories...)
+	}
+	if task.ChecklistItems != nil {
+		taskCopy.ChecklistItems = append([]models.ChecklistItem{}, task.ChecklistItems...)
+	}
+	return taskCopy
+}
+
+// normalizeMemoryTask sets the attributes of a task that are missing to the values
+// read from a Taskwarrior task without them.
+func normalizeMemoryTask(task *models.TaskwarriorTask) {
+	for _, attr := range []**string{
+		&task.ToDoListID,
+		&task.ToDoTaskID,
+		&task.Title,
+		&task.CompletedAt,
+		&task.DueAt,
+		&task.Note,
+	} {
+		if *attr == nil {
+			*attr = new(string)
+		}
+	}
+	if task.Importance == nil {
+		importance := models.TODO_IMPORTANCE_NORMAL
+		task.Importance = &importance
+	}
+	if task.Categories == nil {
+		task.Categories = []string{}
+	}
+	if !AreChecklistItemsSynced() {
+		task.ChecklistItems = nil
+	} else if task.ChecklistItems == nil {
+		task.ChecklistItems = []models.ChecklistItem{}
+	}
+}
+
+// withDefaultTags adds the default tags of a list to the categories of a task, as they
+// are read from Taskwarrior.
+func withDefaultTags(categories []string, defaults *ListDefaults) []string {
+	tagged := append([]string{}, categories...)
+	for _, tag := range defaults.Tags {
+		isTagged := false
+		for _, category := range tagged {
+			if category == tag {
+				isTagged = true
+				break
+			}
+		}
+		if !isTagged {
+			tagged = append(tagged, tag)
+		}
+	}
+	return tagged
+}
+
+// isPendingStatus returns 'true' if the task matches the Taskwarrior filter
+// 'status:pending'.
+func isPendingStatus(status models.TaskStatus) bool {
+	return status == models.TW_TASKSTATUS_PENDING || status == models.TW_TASKSTATUS_STARTED
+}
+
+// memoryNow returns the current time at the precision of the 'modified' date of
+// Taskwarrior.
+func memoryNow() *time.Time {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &now
+}
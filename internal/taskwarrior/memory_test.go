@@ -0,0 +1,118 @@
+package taskwarrior
+
+import (
+	"testing"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMemoryToDoTask(listID string, taskID string, title string) *models.Task {
+	return &models.Task{
+		ToDoListID: &listID,
+		ToDoTaskID: &taskID,
+		Title:      &title,
+		Categories: []string{},
+		Status:     models.TW_TASKSTATUS_PENDING,
+	}
+}
+
+func TestMemoryStore_import_createdOnceWithDefaults(t *testing.T) {
+	store := NewMemoryStore()
+	defaults := &ListDefaults{Project: "home", Tags: []string{"shopping"}}
+
+	result, err := store.Import(newMemoryToDoTask("list-1", "task-1", "Milk"), defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, TASK_CREATED, result)
+	result, err = store.Import(newMemoryToDoTask("list-1", "task-1", "Milk"), defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, TASK_EXISTS_AND_SKIPPED, result)
+
+	tasks, err := store.ReadTasksAll()
+	assert.NoError(t, err)
+	if assert.Len(t, *tasks, 1) {
+		assert.Equal(t, "Milk", *(*tasks)[0].Title)
+		assert.Equal(t, []string{"shopping"}, (*tasks)[0].Categories)
+		assert.Equal(t, "", *(*tasks)[0].CompletedAt)
+	}
+	projects, err := store.ReadProjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"home"}, projects)
+}
+
+func TestMemoryStore_import_recurringTask_isCreatedWithoutRecurrence(t *testing.T) {
+	store := NewMemoryStore()
+	task := newMemoryToDoTask("list-1", "task-1", "Water plants")
+	task.Recurrence = &models.Recurrence{}
+
+	result, err := store.Import(task, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, TASK_CREATED_WITHOUT_RECURRENCE, result)
+}
+
+func TestMemoryStore_update_unknownTask_isError(t *testing.T) {
+	store := NewMemoryStore()
+	uuid := "unknown"
+
+	err := store.Update(&models.TaskwarriorTask{
+		Task:            *newMemoryToDoTask("list-1", "task-1", "Milk"),
+		TaskWarriorUUID: &uuid,
+	}, nil)
+
+	assert.ErrorContains(t, err, "no Taskwarrior task exists")
+}
+
+func TestMemoryStore_pushedTaskIsLinked(t *testing.T) {
+	store := NewMemoryStore()
+	title := "Report"
+	task := models.Task{Title: &title, Status: models.TW_TASKSTATUS_PENDING}
+	uuid := store.AddTask(task, "work")
+	store.AddTask(task, "home")
+	filter := "project.is:'work'"
+
+	tasks, err := store.ReadTasksUnlinked(&filter)
+	assert.NoError(t, err)
+	if assert.Len(t, *tasks, 1) {
+		assert.Equal(t, uuid, *(*tasks)[0].TaskWarriorUUID)
+	}
+
+	err = store.Link(&models.TaskwarriorTask{
+		Task:            *newMemoryToDoTask("list-1", "task-1", title),
+		TaskWarriorUUID: &uuid,
+	})
+	assert.NoError(t, err)
+
+	tasks, err = store.ReadTasksUnlinked(&filter)
+	assert.NoError(t, err)
+	assert.Empty(t, *tasks)
+	listIDs, err := store.ReadProjectListIDs("work")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"list-1"}, listIDs)
+}
+
+func TestMemoryStore_readTasksUnlinked_unsupportedFilter_isError(t *testing.T) {
+	store := NewMemoryStore()
+	filter := "+next"
+
+	_, err := store.ReadTasksUnlinked(&filter)
+
+	assert.ErrorContains(t, err, "Unsupported filter")
+}
+
+func TestMemoryStore_readTasksUnlinked_projectFilterWithQuote(t *testing.T) {
+	store := NewMemoryStore()
+	title := "Call dentist"
+	uuid := store.AddTask(
+		models.Task{Title: &title, Status: models.TW_TASKSTATUS_PENDING},
+		"Bob's",
+	)
+	filter := ProjectFilter("Bob's")
+
+	tasks, err := store.ReadTasksUnlinked(&filter)
+
+	assert.NoError(t, err)
+	if assert.Len(t, *tasks, 1) {
+		assert.Equal(t, uuid, *(*tasks)[0].TaskWarriorUUID)
+	}
+}
@@ -0,0 +1,170 @@
+package taskwarrior
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+)
+
+// Maximum difference between the due date of a MS To-Do occurrence and the due date of
+// the Taskwarrior child task it is linked to. Taskwarrior calculates the due dates of
+// child tasks in local time, thus they can be shifted by daylight saving time.
+const recurrenceDueTolerance = 24 * time.Hour
+
+// createRecurringTask creates a recurring Taskwarrior parent task with the given 'recur'
+// and 'until'. The parent only stores the MS To-Do list ID such that the child tasks
+// generated by Taskwarrior can be linked to the MS To-Do occurrences.
+func createRecurringTask(
+	task *models.Task,
+	recur string,
+	until string,
+	defaults *ListDefaults,
+) error {
+	due, err := models.ConvDateTimeToTW(task.DueAt)
+	if err != nil {
+		return err
+	}
+	args := []string{
+		"add",
+		attrArg(models.UDANameTodoListID, *task.ToDoListID),
+		"due:" + due,
+		"recur:" + recur,
+	}
+	if until != "" {
+		args = append(args, "until:"+until)
+	}
+	args = append(args, "priority:"+defaults.priority(task.Importance))
+	args = append(args, tagModifications(nil, task.Categories, defaults)...)
+	args = append(args, defaults.modifications()...)
+	// The title is not parsed by Taskwarrior, for example 'project:' or '+tag'.
+	args = append(args, "--", *task.Title)
+
+	out, err := taskCommand(args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"[createRecurringTask] Failed to create recurring task: %w\n"+
+				"Output of command: %s\n",
+			err,
+			out,
+		)
+	}
+
+	return nil
+}
+
+// linkRecurringChild links a MS To-Do occurrence of a recurring task to the unlinked
+// Taskwarrior child task with the same title and the closest due date. A child task
+// deleted by deleteSurplusChildren is restored by the link. 'false' is returned if no
+// such child task exists.
+func linkRecurringChild(task *models.Task, defaults *ListDefaults) (bool, error) {
+	// Taskwarrior generates pending child tasks when the tasks are read.
+	children, err := exportTasks(
+		"parent.any:",
+		models.UDANameTodoTaskID+".none:",
+		attrArg(models.UDANameTodoListID, *task.ToDoListID),
+		"(", "status:pending", "or", "status:deleted", ")",
+	)
+	if err != nil {
+		return false, err
+	}
+
+	dueAt, err := time.Parse(models.TODO_DATETIME_FORMAT, *task.DueAt)
+	if err != nil {
+		return false, fmt.Errorf(
+			"[linkRecurringChild] Failed to parse due date '%s': %w\n",
+			*task.DueAt,
+			err,
+		)
+	}
+
+	var child *models.TaskwarriorTask
+	var childDueDiff time.Duration
+	for i, candidate := range *children {
+		if *candidate.Title != *task.Title || *candidate.DueAt == "" {
+			continue
+		}
+		candidateDueAt, err := time.Parse(models.TODO_DATETIME_FORMAT, *candidate.DueAt)
+		if err != nil {
+			return false, err
+		}
+		dueDiff := candidateDueAt.Sub(dueAt)
+		if dueDiff < 0 {
+			dueDiff = -dueDiff
+		}
+		if dueDiff < recurrenceDueTolerance && (child == nil || dueDiff < childDueDiff) {
+			child = &(*children)[i]
+			childDueDiff = dueDiff
+		}
+	}
+	if child == nil {
+		return false, nil
+	}
+
+	linkedChild := &models.TaskwarriorTask{
+		Task:            *task,
+		TaskWarriorUUID: child.TaskWarriorUUID,
+	}
+	err = link(linkedChild)
+	if err != nil {
+		return false, err
+	}
+	err = update(linkedChild, defaults)
+	if err != nil {
+		return true, err
+	}
+
+	return true, deleteSurplusChildren(child.TaskWarriorUUID)
+}
+
+// deleteSurplusChildren deletes the pending child tasks of the recurring task of the
+// given child that are not linked to a MS To-Do occurrence. If the parent is due in the
+// past, Taskwarrior generates a child task for each period up to now, but MS To-Do
+// only has a single open occurrence. The deleted child tasks are restored by
+// linkRecurringChild once MS To-Do creates their occurrence.
+func deleteSurplusChildren(childUUID *string) error {
+	childrenJSON, err := exportTasksJSON(*childUUID)
+	if err != nil {
+		return err
+	}
+	if len(*childrenJSON) != 1 {
+		return errors.New(fmt.Sprintf(
+			"[deleteSurplusChildren] Task with UUID '%s' does not exist.",
+			*childUUID,
+		))
+	}
+	parentUUID, err := parseTaskStringAttrFromJSON("parent", &(*childrenJSON)[0])
+	if err != nil {
+		return err
+	}
+
+	surplusChildren, err := exportTasks(
+		"status:pending",
+		attrArg("parent", parentUUID),
+		models.UDANameTodoTaskID+".none:",
+	)
+	if err != nil {
+		return err
+	}
+	for _, surplusChild := range *surplusChildren {
+		// Only delete this child task, not the recurring task.
+		out, err := taskCommand(
+			"rc.confirmation=off",
+			"rc.recurrence.confirmation=no",
+			*surplusChild.TaskWarriorUUID,
+			"delete",
+		).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf(
+				"[deleteSurplusChildren] Failed to delete child task '%s': %w\n"+
+					"Output of command: %s\n",
+				*surplusChild.TaskWarriorUUID,
+				err,
+				out,
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package taskwarrior
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simachri/taskwarrior-ms-todo/internal/models"
+	testUtils "github.com/simachri/taskwarrior-ms-todo/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport_recurringTask_occurrencesAreLinkedToChildren(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "weekly report"
+	dueAt := "2022-08-02T00:00:00.0000000"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	task := models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		DueAt:      &dueAt,
+		Recurrence: &models.Recurrence{
+			PatternType: models.TODO_RECURRENCE_WEEKLY,
+			Interval:    1,
+			DaysOfWeek:  []string{"tuesday"},
+			RangeType:   models.TODO_RECURRENCERANGE_NOEND,
+		},
+	}
+	result, err := Import(&task, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TASK_CREATED, result)
+
+	tasks, err := ReadTasksAll()
+	assert.NoError(t, err)
+	assert.Len(t, *tasks, 1)
+
+	// MS To-Do creates the next occurrence once the first one is completed.
+	task.Status = models.TW_TASKSTATUS_COMPLETED
+	err = Update(&models.TaskwarriorTask{
+		Task:            task,
+		TaskWarriorUUID: (*tasks)[0].TaskWarriorUUID,
+	}, nil)
+	assert.NoError(t, err)
+
+	nextDueAt := "2022-08-09T00:00:00.0000000"
+	nextToDoTaskID := generateRandomString(10)
+	nextTask := task
+	nextTask.ToDoTaskID = &nextToDoTaskID
+	nextTask.DueAt = &nextDueAt
+	nextTask.Status = models.TW_TASKSTATUS_PENDING
+	result, err = Import(&nextTask, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TASK_LINKED_TO_RECURRENCE, result)
+
+	tasks, err = ReadTasksAll()
+	assert.NoError(t, err)
+	assert.Len(t, *tasks, 2)
+}
+
+func TestImport_recurringTaskDueInPast_surplusChildrenAreDeleted(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "water plants"
+	dueAt := time.Now().UTC().AddDate(0, 0, -5).Format("2006-01-02") + "T00:00:00.0000000"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	task := models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		DueAt:      &dueAt,
+		Recurrence: &models.Recurrence{
+			PatternType: models.TODO_RECURRENCE_DAILY,
+			Interval:    1,
+			RangeType:   models.TODO_RECURRENCERANGE_NOEND,
+		},
+	}
+	result, err := Import(&task, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TASK_CREATED, result)
+
+	children, err := exportTasks("status:pending", "parent.any:")
+	assert.NoError(t, err)
+	if assert.Len(t, *children, 1, "Only the linked child task is pending.") {
+		assert.Equal(t, toDoTaskID, *(*children)[0].ToDoTaskID)
+	}
+
+	// MS To-Do creates the next occurrence once the first one is completed.
+	task.Status = models.TW_TASKSTATUS_COMPLETED
+	err = Update(&models.TaskwarriorTask{
+		Task:            task,
+		TaskWarriorUUID: (*children)[0].TaskWarriorUUID,
+	}, nil)
+	assert.NoError(t, err)
+
+	nextDueAt := time.Now().UTC().AddDate(0, 0, -4).Format("2006-01-02") +
+		"T00:00:00.0000000"
+	nextToDoTaskID := generateRandomString(10)
+	nextTask := task
+	nextTask.ToDoTaskID = &nextToDoTaskID
+	nextTask.DueAt = &nextDueAt
+	nextTask.Status = models.TW_TASKSTATUS_PENDING
+	result, err = Import(&nextTask, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TASK_LINKED_TO_RECURRENCE, result)
+
+	children, err = exportTasks("status:pending", "parent.any:")
+	assert.NoError(t, err)
+	if assert.Len(t, *children, 1) {
+		assert.Equal(t, nextToDoTaskID, *(*children)[0].ToDoTaskID)
+	}
+}
+
+func TestImport_unsupportedRecurrence_isCreatedWithoutRecurrence(t *testing.T) {
+	testUtils.NewTaskwarriorEnv(t)
+	err := CreateIntegrationUDAs()
+	assert.NoError(t, err)
+
+	taskTitle := "monthly meeting"
+	dueAt := "2022-08-01T00:00:00.0000000"
+	toDoListID := generateRandomString(10)
+	toDoTaskID := generateRandomString(10)
+	result, err := Import(&models.Task{
+		Title:      &taskTitle,
+		ToDoListID: &toDoListID,
+		ToDoTaskID: &toDoTaskID,
+		DueAt:      &dueAt,
+		Recurrence: &models.Recurrence{
+			PatternType: models.TODO_RECURRENCE_RELATIVEMONTHLY,
+			Interval:    1,
+		},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TASK_CREATED_WITHOUT_RECURRENCE, result)
+}
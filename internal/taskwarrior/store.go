@@ -0,0 +1,58 @@
+package taskwarrior
+
+import (
+	models "github.com/simachri/taskwarrior-ms-todo/internal/models"
+)
+
+// TaskStore reads and writes the Taskwarrior tasks that are synced with MS To-Do.
+type TaskStore interface {
+	// ReadTasksAll returns the tasks that are linked to a MS To-Do task.
+	ReadTasksAll() (*[]models.TaskwarriorTask, error)
+	ReadTaskByUUID(uuid *string) (*models.TaskwarriorTask, error)
+	// ReadTasksUnlinked returns the pending tasks that match the given filter and are
+	// not yet linked to a MS To-Do task.
+	ReadTasksUnlinked(filter *string) (*[]models.TaskwarriorTask, error)
+	// ReadProjects returns the projects of the pending tasks.
+	ReadProjects() ([]string, error)
+	// ReadProjectListIDs returns the IDs of the MS To-Do lists the tasks of a project
+	// are linked to.
+	ReadProjectListIDs(project string) ([]string, error)
+	Import(task *models.Task, defaults *ListDefaults) (ImportResult, error)
+	Update(task *models.TaskwarriorTask, defaults *ListDefaults) error
+	Link(task *models.TaskwarriorTask) error
+}
+
+// CLIStore is the TaskStore of the Taskwarrior tasks. It runs the 'task' CLI.
+type CLIStore struct{}
+
+func (CLIStore) ReadTasksAll() (*[]models.TaskwarriorTask, error) {
+	return ReadTasksAll()
+}
+
+func (CLIStore) ReadTaskByUUID(uuid *string) (*models.TaskwarriorTask, error) {
+	return ReadTaskByUUID(uuid)
+}
+
+func (CLIStore) ReadTasksUnlinked(filter *string) (*[]models.TaskwarriorTask, error) {
+	return ReadTasksUnlinked(filter)
+}
+
+func (CLIStore) ReadProjects() ([]string, error) {
+	return ReadProjects()
+}
+
+func (CLIStore) ReadProjectListIDs(project string) ([]string, error) {
+	return ReadProjectListIDs(project)
+}
+
+func (CLIStore) Import(task *models.Task, defaults *ListDefaults) (ImportResult, error) {
+	return Import(task, defaults)
+}
+
+func (CLIStore) Update(task *models.TaskwarriorTask, defaults *ListDefaults) error {
+	return Update(task, defaults)
+}
+
+func (CLIStore) Link(task *models.TaskwarriorTask) error {
+	return Link(task)
+}
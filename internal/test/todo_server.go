@@ -0,0 +1,572 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeTask is a task of the fake To-Do server as JSON object of the Microsoft Graph API,
+// for example {"title": "Milk", "status": "notStarted"}.
+type FakeTask map[string]interface{}
+
+// fakeList is a To-Do list of the fake To-Do server.
+type fakeList struct {
+	id          string
+	displayName string
+	// Tasks by their ID.
+	tasks map[string]FakeTask
+	// Version of the server at which a task has been created or changed last. Key is the
+	// task ID.
+	taskVersions map[string]int
+	// Version of the server at which a task has been deleted. Key is the task ID.
+	removedVersions map[string]int
+}
+
+// FakeToDoServer is an HTTP server with in-memory state that serves the part of the
+// Microsoft Graph API that is used to sync MS To-Do: '/me', '/me/todo/lists',
+// '/me/todo/lists/{id}/tasks' including the delta query and JSON batches. Its URL is
+// the base URL of a GraphClient.
+type FakeToDoServer struct {
+	*httptest.Server
+	// Number of lists per page, MS To-Do pages the lists without '$top'. All lists are
+	// returned in one page if it is 0.
+	ListPageSize int
+	mutex        sync.Mutex
+	lists        []*fakeList
+	// Incremented by each change of a task. The delta token is derived from the version
+	// at the query.
+	version int
+	// Delta tokens below this value are rejected as expired.
+	minDeltaToken int
+	nextID        int
+}
+
+var (
+	fakeListsPath = regexp.MustCompile(`^/me/todo/lists/?$`)
+	fakeTasksPath = regexp.MustCompile(`^/me/todo/lists/([^/]+)/tasks/?$`)
+	fakeTaskPath  = regexp.MustCompile(`^/me/todo/lists/([^/]+)/tasks/([^/]+)$`)
+	fakeDeltaPath = regexp.MustCompile(
+		`^/me/todo/lists/([^/]+)/tasks/(microsoft\.graph\.)?delta\(\)$`,
+	)
+	fakeStatusFilter = regexp.MustCompile(`^status (eq|ne) '([A-Za-z]+)'$`)
+)
+
+// NewFakeToDoServer starts a fake To-Do server without lists that is closed at the end
+// of the test.
+func NewFakeToDoServer(t *testing.T) *FakeToDoServer {
+	server := &FakeToDoServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// AddList creates a To-Do list and returns its ID.
+func (server *FakeToDoServer) AddList(displayName string) string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.addList(displayName).id
+}
+
+// AddTask creates a task in a To-Do list and returns its ID. Missing attributes get the
+// defaults of MS To-Do.
+func (server *FakeToDoServer) AddTask(listID string, task FakeTask) string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	list := server.findList(listID)
+	if list == nil {
+		panic(fmt.Sprintf("[FakeToDoServer] No list with ID '%s'.", listID))
+	}
+	return server.addTask(list, task)["id"].(string)
+}
+
+// Task returns a copy of a task. 'false' is returned if the task does not exist.
+func (server *FakeToDoServer) Task(listID string, taskID string) (FakeTask, bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	list := server.findList(listID)
+	if list == nil || list.tasks[taskID] == nil {
+		return nil, false
+	}
+	return copyTask(list.tasks[taskID]), true
+}
+
+// UpdateTask changes the given attributes of a task as if it has been changed in
+// MS To-Do.
+func (server *FakeToDoServer) UpdateTask(listID string, taskID string, changes FakeTask) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	list := server.findList(listID)
+	if list == nil || list.tasks[taskID] == nil {
+		panic(fmt.Sprintf("[FakeToDoServer] No task with ID '%s'.", taskID))
+	}
+	server.updateTask(list, taskID, changes)
+}
+
+// DeleteTask deletes a task as if it has been deleted in MS To-Do.
+func (server *FakeToDoServer) DeleteTask(listID string, taskID string) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	list := server.findList(listID)
+	if list == nil {
+		return
+	}
+	server.deleteTask(list, taskID)
+}
+
+// ExpireDeltaTokens lets all delta links issued so far expire.
+func (server *FakeToDoServer) ExpireDeltaTokens() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.minDeltaToken = server.version + 2
+}
+
+func (server *FakeToDoServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	body, err := readFakeRequestBody(r)
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, "invalidRequest", err.Error())
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/$batch" {
+		server.serveBatch(w, body)
+		return
+	}
+	server.serve(w, r.Method, r.URL, body)
+}
+
+// serve handles a request. It is called with the mutex locked, also for the requests of
+// a JSON batch.
+func (server *FakeToDoServer) serve(
+	w http.ResponseWriter,
+	method string,
+	reqURL *url.URL,
+	body []byte,
+) {
+	path := reqURL.Path
+
+	if path == "/me" && method == http.MethodGet {
+		writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":          "fake-user",
+			"displayName": "Fake User",
+		})
+		return
+	}
+
+	if fakeListsPath.MatchString(path) {
+		switch method {
+		case http.MethodGet:
+			server.serveLists(w, reqURL)
+		case http.MethodPost:
+			var listData struct {
+				DisplayName string `json:"displayName"`
+			}
+			if err := json.Unmarshal(body, &listData); err != nil {
+				writeFakeError(w, http.StatusBadRequest, "invalidRequest", err.Error())
+				return
+			}
+			list := server.addList(listData.DisplayName)
+			writeFakeJSON(w, http.StatusCreated, convFakeList(list))
+		default:
+			writeFakeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", method)
+		}
+		return
+	}
+
+	if match := fakeDeltaPath.FindStringSubmatch(path); match != nil {
+		server.serveDelta(w, match[1], reqURL.Query())
+		return
+	}
+
+	if match := fakeTasksPath.FindStringSubmatch(path); match != nil {
+		list := server.findList(match[1])
+		if list == nil {
+			writeFakeError(w, http.StatusNotFound, "ErrorItemNotFound", "No such list.")
+			return
+		}
+		switch method {
+		case http.MethodGet:
+			server.serveTasks(w, list, reqURL)
+		case http.MethodPost:
+			var task FakeTask
+			if err := json.Unmarshal(body, &task); err != nil {
+				writeFakeError(w, http.StatusBadRequest, "invalidRequest", err.Error())
+				return
+			}
+			writeFakeJSON(w, http.StatusCreated, server.addTask(list, task))
+		default:
+			writeFakeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", method)
+		}
+		return
+	}
+
+	if match := fakeTaskPath.FindStringSubmatch(path); match != nil {
+		list := server.findList(match[1])
+		if list == nil || list.tasks[match[2]] == nil {
+			writeFakeError(w, http.StatusNotFound, "ErrorItemNotFound", "No such task.")
+			return
+		}
+		taskID := match[2]
+		switch method {
+		case http.MethodGet:
+			writeFakeJSON(w, http.StatusOK, expandTask(
+				list.tasks[taskID],
+				reqURL.Query().Get("$expand"),
+			))
+		case http.MethodPatch:
+			var changes FakeTask
+			if err := json.Unmarshal(body, &changes); err != nil {
+				writeFakeError(w, http.StatusBadRequest, "invalidRequest", err.Error())
+				return
+			}
+			server.updateTask(list, taskID, changes)
+			writeFakeJSON(w, http.StatusOK, list.tasks[taskID])
+		case http.MethodDelete:
+			server.deleteTask(list, taskID)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeFakeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", method)
+		}
+		return
+	}
+
+	writeFakeError(w, http.StatusNotFound, "UnknownPath", method+" "+path)
+}
+
+// serveLists returns the lists paged by 'ListPageSize' and '$skiptoken'.
+func (server *FakeToDoServer) serveLists(w http.ResponseWriter, reqURL *url.URL) {
+	query := reqURL.Query()
+
+	skip, _ := strconv.Atoi(query.Get("$skiptoken"))
+	if skip > len(server.lists) {
+		skip = len(server.lists)
+	}
+	pageLists := server.lists[skip:]
+
+	response := map[string]interface{}{}
+	if server.ListPageSize > 0 && server.ListPageSize < len(pageLists) {
+		pageLists = pageLists[:server.ListPageSize]
+		query.Set("$skiptoken", strconv.Itoa(skip+server.ListPageSize))
+		response["@odata.nextLink"] = server.URL + reqURL.Path + "?" + query.Encode()
+	}
+
+	lists := []interface{}{}
+	for _, list := range pageLists {
+		lists = append(lists, convFakeList(list))
+	}
+	response["value"] = lists
+
+	writeFakeJSON(w, http.StatusOK, response)
+}
+
+// serveTasks returns the tasks of a list that match the '$filter' on the status. The
+// tasks are paged by '$top' and '$skiptoken'.
+func (server *FakeToDoServer) serveTasks(
+	w http.ResponseWriter,
+	list *fakeList,
+	reqURL *url.URL,
+) {
+	query := reqURL.Query()
+
+	var filterOp, filterStatus string
+	if filter := query.Get("$filter"); filter != "" {
+		match := fakeStatusFilter.FindStringSubmatch(filter)
+		if match == nil {
+			writeFakeError(w, http.StatusBadRequest, "invalidRequest", "Unsupported filter.")
+			return
+		}
+		filterOp, filterStatus = match[1], match[2]
+	}
+
+	tasks := []interface{}{}
+	for _, taskID := range sortedTaskIDs(list.tasks) {
+		task := list.tasks[taskID]
+		isStatus := task["status"] == filterStatus
+		if (filterOp == "eq" && !isStatus) || (filterOp == "ne" && isStatus) {
+			continue
+		}
+		tasks = append(tasks, expandTask(task, query.Get("$expand")))
+	}
+
+	skip, _ := strconv.Atoi(query.Get("$skiptoken"))
+	if skip > len(tasks) {
+		skip = len(tasks)
+	}
+	tasks = tasks[skip:]
+
+	response := map[string]interface{}{}
+	top, _ := strconv.Atoi(query.Get("$top"))
+	if top > 0 && top < len(tasks) {
+		tasks = tasks[:top]
+		query.Set("$skiptoken", strconv.Itoa(skip+top))
+		response["@odata.nextLink"] = server.URL + reqURL.Path + "?" + query.Encode()
+	}
+	response["value"] = tasks
+
+	writeFakeJSON(w, http.StatusOK, response)
+}
+
+// serveDelta returns the tasks of a list that have been created, changed or deleted
+// since the version given by the '$deltatoken'. Without delta token, all tasks are
+// returned.
+func (server *FakeToDoServer) serveDelta(
+	w http.ResponseWriter,
+	listID string,
+	query url.Values,
+) {
+	list := server.findList(listID)
+	if list == nil {
+		writeFakeError(w, http.StatusNotFound, "ErrorItemNotFound", "No such list.")
+		return
+	}
+
+	// The delta token is the version of the server at the query plus 1, such that it is
+	// never 0.
+	hasDeltaToken := false
+	since := 0
+	if deltaToken := query.Get("$deltatoken"); deltaToken != "" {
+		token, err := strconv.Atoi(deltaToken)
+		if err != nil || token < server.minDeltaToken {
+			writeFakeError(w, http.StatusGone, "syncStateNotFound", "Delta token expired.")
+			return
+		}
+		hasDeltaToken = true
+		since = token - 1
+	}
+
+	tasks := []interface{}{}
+	for _, taskID := range sortedTaskIDs(list.tasks) {
+		if !hasDeltaToken || list.taskVersions[taskID] > since {
+			tasks = append(tasks, list.tasks[taskID])
+		}
+	}
+	if hasDeltaToken {
+		for taskID, version := range list.removedVersions {
+			if version > since {
+				tasks = append(tasks, map[string]interface{}{
+					"id":       taskID,
+					"@removed": map[string]interface{}{"reason": "deleted"},
+				})
+			}
+		}
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"value": tasks,
+		"@odata.deltaLink": fmt.Sprintf(
+			"%s/me/todo/lists/%s/tasks/delta()?$deltatoken=%v",
+			server.URL,
+			url.PathEscape(listID),
+			server.version+1,
+		),
+	})
+}
+
+// serveBatch handles the requests of a JSON batch one after the other.
+func (server *FakeToDoServer) serveBatch(w http.ResponseWriter, body []byte) {
+	var batch struct {
+		Requests []struct {
+			ID     string          `json:"id"`
+			Method string          `json:"method"`
+			URL    string          `json:"url"`
+			Body   json.RawMessage `json:"body"`
+		} `json:"requests"`
+	}
+	if err := json.Unmarshal(body, &batch); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "invalidRequest", err.Error())
+		return
+	}
+
+	responses := []interface{}{}
+	for _, request := range batch.Requests {
+		reqURL, err := url.Parse(request.URL)
+		if err != nil {
+			writeFakeError(w, http.StatusBadRequest, "invalidRequest", err.Error())
+			return
+		}
+
+		recorder := httptest.NewRecorder()
+		server.serve(recorder, request.Method, reqURL, request.Body)
+
+		headers := map[string]string{}
+		for name := range recorder.Header() {
+			headers[name] = recorder.Header().Get(name)
+		}
+		var responseBody interface{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+		responses = append(responses, map[string]interface{}{
+			"id":      request.ID,
+			"status":  recorder.Code,
+			"headers": headers,
+			"body":    responseBody,
+		})
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{"responses": responses})
+}
+
+func (server *FakeToDoServer) findList(listID string) *fakeList {
+	for _, list := range server.lists {
+		if list.id == listID {
+			return list
+		}
+	}
+	return nil
+}
+
+func (server *FakeToDoServer) addList(displayName string) *fakeList {
+	server.nextID++
+	list := &fakeList{
+		id:              fmt.Sprintf("list-%v", server.nextID),
+		displayName:     displayName,
+		tasks:           map[string]FakeTask{},
+		taskVersions:    map[string]int{},
+		removedVersions: map[string]int{},
+	}
+	server.lists = append(server.lists, list)
+	return list
+}
+
+func (server *FakeToDoServer) addTask(list *fakeList, taskData FakeTask) FakeTask {
+	server.nextID++
+	server.version++
+	now := fakeNow()
+
+	task := FakeTask{
+		"status":               "notStarted",
+		"importance":           "normal",
+		"body":                 map[string]interface{}{"content": "", "contentType": "text"},
+		"categories":           []interface{}{},
+		"checklistItems":       []interface{}{},
+		"createdDateTime":      now,
+		"lastModifiedDateTime": now,
+	}
+	for key, value := range taskData {
+		task[key] = value
+	}
+	task["id"] = fmt.Sprintf("task-%v", server.nextID)
+
+	taskID := task["id"].(string)
+	list.tasks[taskID] = task
+	list.taskVersions[taskID] = server.version
+	return task
+}
+
+func (server *FakeToDoServer) updateTask(list *fakeList, taskID string, changes FakeTask) {
+	server.version++
+	task := list.tasks[taskID]
+	for key, value := range changes {
+		// A property set to 'null' is cleared, MS To-Do does not return it anymore.
+		if value == nil {
+			delete(task, key)
+			continue
+		}
+		task[key] = value
+	}
+	task["id"] = taskID
+	task["lastModifiedDateTime"] = fakeNow()
+	list.taskVersions[taskID] = server.version
+}
+
+func (server *FakeToDoServer) deleteTask(list *fakeList, taskID string) {
+	if list.tasks[taskID] == nil {
+		return
+	}
+	server.version++
+	delete(list.tasks, taskID)
+	delete(list.taskVersions, taskID)
+	list.removedVersions[taskID] = server.version
+}
+
+// convFakeList converts a list into its JSON object of the Microsoft Graph API.
+func convFakeList(list *fakeList) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                list.id,
+		"displayName":       list.displayName,
+		"wellknownListName": "none",
+		"isOwner":           true,
+		"isShared":          false,
+	}
+}
+
+// expandTask returns the task without its checklist items unless they are expanded.
+func expandTask(task FakeTask, expand string) FakeTask {
+	if strings.Contains(expand, "checklistItems") {
+		return task
+	}
+	expanded := copyTask(task)
+	delete(expanded, "checklistItems")
+	return expanded
+}
+
+func copyTask(task FakeTask) FakeTask {
+	taskCopy := FakeTask{}
+	for key, value := range task {
+		taskCopy[key] = value
+	}
+	return taskCopy
+}
+
+func sortedTaskIDs(tasks map[string]FakeTask) []string {
+	taskIDs := []string{}
+	for taskID := range tasks {
+		taskIDs = append(taskIDs, taskID)
+	}
+	sort.Strings(taskIDs)
+	return taskIDs
+}
+
+// fakeNow returns the current time in the format of the Microsoft Graph API.
+func fakeNow() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// readFakeRequestBody reads the body of a request, which the Microsoft Graph SDK
+// compresses by gzip.
+func readFakeRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil || r.Header.Get("Content-Encoding") != "gzip" {
+		return body, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, code string, message string) {
+	writeFakeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"code": code, "message": message},
+	})
+}